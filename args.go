@@ -0,0 +1,165 @@
+package flagtag
+
+import (
+	"flag"
+	"strings"
+)
+
+// visitFlagArgs walks the flag arguments in args in the same way as the flag
+// set would while parsing them. For every flag argument, fn is called with the
+// index of the argument and the flag name as specified. fn returns the name of
+// the flag that the argument refers to, which is used to determine whether the
+// next argument is the flag's value. Walking stops at the first non-flag
+// argument, at the '--' terminator or at the first error returned by fn.
+func visitFlagArgs(flagset *flag.FlagSet, args []string, fn func(index int, name string) (string, error)) error {
+	for i := 0; i < len(args); i++ {
+		_, name, hasValue, ok := splitFlagArg(args[i])
+		if !ok {
+			return nil
+		}
+		resolved, err := fn(i, name)
+		if err != nil {
+			return err
+		}
+		if !hasValue {
+			if f := flagset.Lookup(resolved); f != nil && !isBoolFlag(f.Value) {
+				// skip the flag's value
+				i++
+			}
+		}
+	}
+	return nil
+}
+
+// splitFlagArg splits a flag argument into its leading dashes and the flag
+// name. hasValue indicates whether the argument contains the value as well,
+// as in '-name=value'. ok is false if the argument is not a (well-formed) flag
+// argument, in which case the flag package would stop parsing or report an
+// error.
+func splitFlagArg(arg string) (dashes string, name string, hasValue bool, ok bool) {
+	if len(arg) < 2 || arg[0] != '-' {
+		return "", "", false, false
+	}
+	dashes = "-"
+	if arg[1] == '-' {
+		dashes = "--"
+		if len(arg) == 2 {
+			return "", "", false, false
+		}
+	}
+	name = arg[len(dashes):]
+	if len(name) == 0 || name[0] == '-' || name[0] == '=' {
+		return "", "", false, false
+	}
+	if i := strings.IndexByte(name, '='); i >= 0 {
+		name = name[:i]
+		hasValue = true
+	}
+	return dashes, name, hasValue, true
+}
+
+// isBoolFlag checks whether the provided value is a boolean flag, i.e. a flag
+// that does not require a value.
+func isBoolFlag(value flag.Value) bool {
+	b, ok := value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
+
+// isHelpFlag checks whether the name is one of the help flags that the flag
+// package handles implicitly.
+func isHelpFlag(flagset *flag.FlagSet, name string) bool {
+	return (name == "help" || name == "h") && flagset.Lookup(name) == nil
+}
+
+// expandAbbreviations replaces unambiguous prefixes of flag names in args with
+// the full flag names. Arguments that exactly match a flag name are left
+// untouched. Former names of flags are not considered for abbreviation. An
+// error of type ErrAmbiguousFlag is returned if a prefix matches multiple
+// flags.
+func expandAbbreviations(flagset *flag.FlagSet, args []string) ([]string, error) {
+	var result = append([]string(nil), args...)
+	err := visitFlagArgs(flagset, result, func(index int, name string) (string, error) {
+		if flagset.Lookup(name) != nil || isHelpFlag(flagset, name) {
+			return name, nil
+		}
+		var candidates = abbreviationCandidates(flagset, name)
+		switch len(candidates) {
+		case 0:
+			// unknown flag, leave it to the flag set to report
+			return name, nil
+		case 1:
+			dashes, _, _, _ := splitFlagArg(result[index])
+			result[index] = dashes + candidates[0] + result[index][len(dashes)+len(name):]
+			return candidates[0], nil
+		default:
+			return "", &ErrAmbiguousFlag{Name: name, Candidates: candidates}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// abbreviationCandidates returns the names of the flags of which the provided
+// name is a prefix. Hidden flags and former names of flags are not
+// considered, such that they can only be used by their full name.
+func abbreviationCandidates(flagset *flag.FlagSet, name string) []string {
+	var state = lookupState(flagset)
+	var candidates []string
+	flagset.VisitAll(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Name, name) || state.alias(f.Name) != nil {
+			return
+		}
+		var record = state.record(f.Name)
+		if record == nil {
+			record = state.fileRecord(f.Name)
+		}
+		if record != nil && record.tag.Options.Hidden {
+			return
+		}
+		candidates = append(candidates, f.Name)
+	})
+	return candidates
+}
+
+// isInputFlagArg returns a function that checks whether an argument is a flag
+// argument for a flag with the 'input' flag option that is followed by a
+// separate value argument, taking abbreviations into account if they are
+// enabled. Such values must not be expanded as response files, since '@path'
+// refers to the file to read the flag's value from.
+func isInputFlagArg(flagset *flag.FlagSet) func(arg string) bool {
+	var state = lookupState(flagset)
+	return func(arg string) bool {
+		_, name, hasValue, ok := splitFlagArg(arg)
+		if !ok || hasValue {
+			return false
+		}
+		if flagset.Lookup(name) == nil && state.abbreviations {
+			if candidates := abbreviationCandidates(flagset, name); len(candidates) == 1 {
+				name = candidates[0]
+			}
+		}
+		var record = state.record(name)
+		if record == nil {
+			record = state.alias(name)
+		}
+		return record != nil && record.tag.Options.Input
+	}
+}
+
+// ErrAmbiguousFlag is an error type for the case where an abbreviated flag name
+// matches multiple flags.
+type ErrAmbiguousFlag struct {
+	// Name is the abbreviated flag name as specified.
+	Name string
+	// Candidates are the names of all flags that match the abbreviation.
+	Candidates []string
+}
+
+// Error returns the error listing all candidate flags.
+func (e *ErrAmbiguousFlag) Error() string {
+	return "ambiguous flag: -" + e.Name + " could mean -" + strings.Join(e.Candidates, ", -")
+}
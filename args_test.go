@@ -0,0 +1,125 @@
+package flagtag
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestSplitFlagArg(t *testing.T) {
+	var testset = []struct {
+		arg      string
+		dashes   string
+		name     string
+		hasValue bool
+		ok       bool
+	}{
+		{"-a", "-", "a", false, true},
+		{"--a", "--", "a", false, true},
+		{"-a=b", "-", "a", true, true},
+		{"--name=", "--", "name", true, true},
+		{"-", "", "", false, false},
+		{"--", "", "", false, false},
+		{"---a", "", "", false, false},
+		{"-=a", "", "", false, false},
+		{"value", "", "", false, false},
+	}
+	for nr, test := range testset {
+		dashes, name, hasValue, ok := splitFlagArg(test.arg)
+		if dashes != test.dashes || name != test.name || hasValue != test.hasValue || ok != test.ok {
+			t.Error("Test entry", nr, "failed:", dashes, name, hasValue, ok)
+		}
+	}
+}
+
+func newAbbreviationFlagset() *flag.FlagSet {
+	fs := flag.NewFlagSet("abbreviations", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Bool("verbose", false, "")
+	fs.Bool("version", false, "")
+	fs.String("output", "", "")
+	fs.String("o", "", "")
+	EnableAbbreviations(fs)
+	return fs
+}
+
+func TestExpandAbbreviations(t *testing.T) {
+	fs := newAbbreviationFlagset()
+	result, err := expandAbbreviations(fs, []string{"-verb", "--out=file", "-ou", "-verb", "-o", "x", "-vers", "arg", "-verb"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []string{"-verbose", "--output=file", "-output", "-verb", "-o", "x", "-version", "arg", "-verb"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("Expected %q, but got %q", expected, result)
+	}
+}
+
+func TestExpandAbbreviationsAmbiguous(t *testing.T) {
+	fs := newAbbreviationFlagset()
+	_, err := expandAbbreviations(fs, []string{"-ver"})
+	ambiguous, ok := err.(*ErrAmbiguousFlag)
+	if !ok {
+		t.Fatal("Expected error of type ErrAmbiguousFlag, but got", err)
+	}
+	if ambiguous.Name != "ver" || !reflect.DeepEqual(ambiguous.Candidates, []string{"verbose", "version"}) {
+		t.Fatal("Unexpected ambiguity information:", ambiguous)
+	}
+	if ambiguous.Error() != "ambiguous flag: -ver could mean -verbose, -version" {
+		t.Fatal("Unexpected error message:", ambiguous.Error())
+	}
+}
+
+func TestParseWithAbbreviations(t *testing.T) {
+	var s = struct {
+		Verbose bool   `flag:"verbose,false,Verbose output."`
+		Name    string `flag:"name,,The name."`
+	}{}
+	fs := flag.NewFlagSet("parseabbreviations", flag.ContinueOnError)
+	EnableAbbreviations(fs)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-verb", "-na", "abbreviated"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !s.Verbose || s.Name != "abbreviated" {
+		t.Fatal("Expected abbreviated flags to be set, but got", s)
+	}
+}
+
+func TestParseWithAbbreviationsHidden(t *testing.T) {
+	type config struct {
+		Verbose bool   `flag:"verbose,false,Verbose output."`
+		Debug   bool   `flag:"verbose-internal,false,Internal debugging." flagopt:"hidden"`
+		Key     string `flag:"verify-key,,Key." flagopt:"hidden,file"`
+	}
+	var testset = []struct {
+		args     []string
+		expected config
+	}{
+		{[]string{"-ver"}, config{Verbose: true}},
+		{[]string{"-verbose-internal"}, config{Debug: true}},
+		{[]string{"-verify-key", "k"}, config{Key: "k"}},
+	}
+	for _, test := range testset {
+		var s config
+		fs := flag.NewFlagSet("parseabbreviationshidden", flag.ContinueOnError)
+		EnableAbbreviations(fs)
+		if err := ConfigureFlagsetAndParseArgs(&s, fs, test.args); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if s != test.expected {
+			t.Error("Unexpected configuration for", test.args, ":", s)
+		}
+	}
+}
+
+func TestParseWithoutAbbreviations(t *testing.T) {
+	var s = struct {
+		Verbose bool `flag:"verbose,false,Verbose output."`
+	}{}
+	fs := flag.NewFlagSet("parsenoabbreviations", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-verb"}); err == nil {
+		t.Fatal("Expected an error since abbreviations are not enabled.")
+	}
+}
@@ -0,0 +1,113 @@
+package flagtag
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// encodingRaw indicates that the value is taken as is.
+	encodingRaw = "raw"
+	// encodingHex indicates that the value is hex encoded.
+	encodingHex = "hex"
+	// encodingBase64 indicates that the value is encoded with standard base64
+	// encoding.
+	encodingBase64 = "base64"
+	// encodingBase64URL indicates that the value is encoded with URL-safe
+	// base64 encoding.
+	encodingBase64URL = "base64url"
+)
+
+// isByteSlice checks whether the type is a slice of bytes.
+func isByteSlice(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
+
+// registerBytesFlag registers a flag for a []byte field. The value is decoded
+// according to the 'encoding' flag option and its length is validated
+// according to the 'len' flag option. The default value is encoded in the same
+// way.
+func registerBytesFlag(fieldName string, fieldValue reflect.Value, tag *flagTag, flagset *flag.FlagSet) error {
+	var value = &bytesValue{field: fieldValue, encoding: tag.Options.Encoding, length: tag.Options.Len}
+	switch value.encoding {
+	case "":
+		value.encoding = encodingRaw
+	case encodingRaw, encodingHex, encodingBase64, encodingBase64URL:
+	default:
+		return errors.New("field '" + fieldName + "' (tag '" + tag.Name + "'): unsupported encoding '" + value.encoding + "'")
+	}
+	if value.length < 0 {
+		return errors.New("field '" + fieldName + "' (tag '" + tag.Name + "'): invalid value for flag option 'len'")
+	}
+	if tag.DefaultValue != "" {
+		if err := value.Set(tag.DefaultValue); err != nil {
+			return &ErrInvalidDefault{fieldName, tag.Name, err}
+		}
+	}
+	flagset.Var(value, tag.Name, tag.Description)
+	return nil
+}
+
+// bytesValue is the flag value of a []byte field.
+type bytesValue struct {
+	field    reflect.Value
+	encoding string
+	length   int
+}
+
+func (v *bytesValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	var data = v.field.Bytes()
+	switch v.encoding {
+	case encodingHex:
+		return hex.EncodeToString(data)
+	case encodingBase64:
+		return base64.StdEncoding.EncodeToString(data)
+	case encodingBase64URL:
+		return base64.URLEncoding.EncodeToString(data)
+	default:
+		return string(data)
+	}
+}
+
+func (v *bytesValue) Set(value string) error {
+	var data []byte
+	var err error
+	switch v.encoding {
+	case encodingHex:
+		data, err = hex.DecodeString(value)
+	case encodingBase64:
+		// padding is optional
+		data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+	case encodingBase64URL:
+		data, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	default:
+		data = []byte(value)
+	}
+	if err != nil {
+		return errors.New("invalid " + v.encoding + " value: " + err.Error())
+	}
+	if v.length > 0 && len(data) != v.length {
+		return errors.New("expected " + strconv.Itoa(v.length) + " bytes, got " + strconv.Itoa(len(data)))
+	}
+	v.field.SetBytes(data)
+	return nil
+}
+
+func (v *bytesValue) typeName() string {
+	if v.encoding == encodingRaw {
+		return "string"
+	}
+	return v.encoding
+}
+
+func (v *bytesValue) Get() interface{} {
+	return v.field.Interface()
+}
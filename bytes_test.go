@@ -0,0 +1,101 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestBytesFlag(t *testing.T) {
+	var s = struct {
+		Raw    []byte `flag:"raw,abc,Raw value."`
+		Hex    []byte `flag:"hex,,Hex value." flagopt:"encoding=hex"`
+		Base64 []byte `flag:"base64,,Base64 value." flagopt:"encoding=base64"`
+		URL    []byte `flag:"url,,URL-safe base64 value." flagopt:"encoding=base64url"`
+		Key    []byte `flag:"key,,Key." flagopt:"encoding=hex,len=4"`
+	}{}
+	fs := flag.NewFlagSet("bytes", flag.ContinueOnError)
+	args := []string{"-hex", "00ff", "-base64", "aGk=", "-url", "-_8", "-key", "01020304"}
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, args); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var testset = []struct {
+		value    []byte
+		expected []byte
+	}{
+		{s.Raw, []byte("abc")},
+		{s.Hex, []byte{0x00, 0xff}},
+		{s.Base64, []byte("hi")},
+		{s.URL, []byte{0xfb, 0xff}},
+		{s.Key, []byte{1, 2, 3, 4}},
+	}
+	for _, test := range testset {
+		if !bytes.Equal(test.value, test.expected) {
+			t.Errorf("Expected %v, but got %v", test.expected, test.value)
+		}
+	}
+	if v := fs.Lookup("url").Value.String(); v != "-_8=" {
+		t.Error("Unexpected string representation:", v)
+	}
+}
+
+func TestBytesFlagInvalidValue(t *testing.T) {
+	var testset = [][]string{
+		{"-key", "zz"},
+		{"-key", "010203"},
+		{"-salt", "!"},
+	}
+	for _, args := range testset {
+		var s = struct {
+			Key  []byte `flag:"key,,Key." flagopt:"encoding=hex,len=2"`
+			Salt []byte `flag:"salt,,Salt." flagopt:"encoding=base64"`
+		}{}
+		fs := flag.NewFlagSet("bytesinvalid", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if ConfigureFlagsetAndParseArgs(&s, fs, args) == nil {
+			t.Error("Expected an error for", args)
+		}
+	}
+}
+
+func TestBytesFlagInvalidOptions(t *testing.T) {
+	var testset = []interface{}{
+		&struct {
+			V []byte `flag:"v,,Value." flagopt:"encoding=base32"`
+		}{},
+		&struct {
+			V []byte `flag:"v,,Value." flagopt:"len=x"`
+		}{},
+		&struct {
+			V []byte `flag:"v,zz,Value." flagopt:"encoding=hex"`
+		}{},
+		&struct {
+			V []byte `flag:"v,,Value." flagopt:"input,encoding=hex"`
+		}{},
+	}
+	for _, config := range testset {
+		if ConfigureFlagset(config, flag.NewFlagSet("bytesoptions", flag.ContinueOnError)) == nil {
+			t.Errorf("Expected an error for %T", config)
+		}
+	}
+}
+
+func TestBytesFlagSecret(t *testing.T) {
+	var s = struct {
+		Key []byte `flag:"key,00112233,Key." flagopt:"encoding=hex,secret"`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("bytessecret", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	writeDefaults(&output, fs, 80)
+	if expected := "  -key hex\n        Key.\n"; output.String() != expected {
+		t.Error("Unexpected usage:", output.String())
+	}
+	if v := fs.Lookup("key").Value.String(); v != "******" {
+		t.Error("Expected redacted value, but got", v)
+	}
+}
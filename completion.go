@@ -0,0 +1,309 @@
+package flagtag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// completeCommand is the hidden command with which shells request completion
+// candidates from the program.
+const completeCommand = "__complete"
+
+// Completer is implemented by flag values that can provide completion
+// candidates for their value, e.g. names of clusters that are only known at
+// runtime.
+type Completer interface {
+	// Complete returns the candidates for the value that start with the
+	// provided prefix.
+	Complete(prefix string) []string
+}
+
+// Complete returns the completion candidates for the last of the provided
+// arguments. The arguments are the command line arguments up to and including
+// the (possibly empty) argument that is being completed. If the argument is
+// the value of a flag whose field implements Completer, the candidates are
+// provided by the field. If the argument starts with a dash, the candidates
+// are the names of the flags of the flag set that are shown in usage
+// information.
+func Complete(flagset *flag.FlagSet, args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	var last = len(args) - 1
+	for i := 0; i < last; i++ {
+		_, name, hasValue, ok := splitFlagArg(args[i])
+		if !ok {
+			// positional arguments are not completed
+			return nil
+		}
+		if f := flagset.Lookup(name); !hasValue && f != nil && !isBoolFlag(f.Value) {
+			i++
+			if i == last {
+				return completeValue(flagset, name, "", args[last])
+			}
+		}
+	}
+	var partial = args[last]
+	if !strings.HasPrefix(partial, "-") {
+		return nil
+	}
+	if dashes, name, hasValue, ok := splitFlagArg(partial); ok && hasValue {
+		return completeValue(flagset, name, dashes+name+"=", partial[len(dashes)+len(name)+1:])
+	}
+	var candidates []string
+	for _, f := range completionFlags(flagset) {
+		for _, dashes := range []string{"-", "--"} {
+			if strings.HasPrefix(dashes+f.name, partial) {
+				candidates = append(candidates, dashes+f.name)
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+// completeValue returns the completion candidates for the value of the named
+// flag. Each candidate is prefixed with the provided prefix.
+func completeValue(flagset *flag.FlagSet, name string, prefix string, partial string) []string {
+	var record = lookupState(flagset).record(name)
+	if record == nil {
+		return nil
+	}
+	var completer = completerOf(record.value)
+	if completer == nil {
+		return nil
+	}
+	var candidates []string
+	for _, candidate := range completer.Complete(partial) {
+		candidates = append(candidates, prefix+candidate)
+	}
+	return candidates
+}
+
+// completerOf returns the Completer implementation of the field value, or nil
+// if the field does not implement Completer.
+func completerOf(value reflect.Value) Completer {
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Kind() != reflect.Interface && value.CanAddr() {
+		if completer, ok := value.Addr().Interface().(Completer); ok {
+			return completer
+		}
+	}
+	if completer, ok := value.Interface().(Completer); ok {
+		return completer
+	}
+	return nil
+}
+
+// WriteCompletion writes a completion script for the provided shell to w. The
+// supported shells are 'bash', 'zsh' and 'fish'. The script completes the
+// flags of the flag set for the command with the provided program name. See
+// WriteBashCompletion for details on the completions.
+func WriteCompletion(w io.Writer, flagset *flag.FlagSet, shell string, program string) error {
+	switch shell {
+	case "bash":
+		return WriteBashCompletion(w, flagset, program)
+	case "zsh":
+		return WriteZshCompletion(w, flagset, program)
+	case "fish":
+		return WriteFishCompletion(w, flagset, program)
+	default:
+		return errors.New("unsupported shell '" + shell + "'")
+	}
+}
+
+// WriteBashCompletion writes a bash completion script to w. The script
+// completes the flags of the flag set for the command with the provided
+// program name. Hidden flags and former names of flags are not completed.
+// Values of flags with the 'complete=file' or 'complete=dir' flag option are
+// completed as file or directory paths respectively. If dynamic completion is
+// enabled for the flag set (see EnableCompletion), the completion of values
+// that implement Completer is delegated to the program.
+func WriteBashCompletion(w io.Writer, flagset *flag.FlagSet, program string) error {
+	var flags = completionFlags(flagset)
+	var b strings.Builder
+	var function = "_" + shellIdentifier(program) + "_completion"
+	fmt.Fprintf(&b, "# bash completion for %s\n", program)
+	fmt.Fprintf(&b, "%s() {\n", function)
+	b.WriteString("    local cur prev\n")
+	b.WriteString("    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	b.WriteString("    case \"$prev\" in\n")
+	for _, f := range flags {
+		if f.isBool {
+			continue
+		}
+		fmt.Fprintf(&b, "        -%s|--%s)\n", f.name, f.name)
+		switch {
+		case f.dynamic:
+			fmt.Fprintf(&b, "            mapfile -t COMPREPLY < <(\"${COMP_WORDS[0]}\" %s \"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null)\n", completeCommand)
+		case f.complete == completeFile:
+			b.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		case f.complete == completeDir:
+			b.WriteString("            COMPREPLY=($(compgen -d -- \"$cur\"))\n")
+		}
+		b.WriteString("            return 0\n")
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	var names []string
+	for _, f := range flags {
+		names = append(names, "-"+f.name)
+	}
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(names, " ")))
+	b.WriteString("        return 0\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", function, shellQuote(program))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteZshCompletion writes a zsh completion script to w. See
+// WriteBashCompletion for details on the completions.
+func WriteZshCompletion(w io.Writer, flagset *flag.FlagSet, program string) error {
+	var b strings.Builder
+	var flags = completionFlags(flagset)
+	var function = "_" + shellIdentifier(program) + "_dynamic"
+	fmt.Fprintf(&b, "#compdef %s\n\n", program)
+	for _, f := range flags {
+		if f.dynamic {
+			fmt.Fprintf(&b, "%s() {\n", function)
+			b.WriteString("  local -a candidates\n")
+			fmt.Fprintf(&b, "  candidates=(\"${(@f)$(${words[1]} %s \"${(@)words[2,CURRENT]}\" 2>/dev/null)}\")\n", completeCommand)
+			b.WriteString("  compadd -a candidates\n")
+			b.WriteString("}\n\n")
+			break
+		}
+	}
+	b.WriteString("_arguments \\\n")
+	for _, f := range flags {
+		var spec = "-" + f.name + "[" + zshEscape(f.usage) + "]"
+		if !f.isBool {
+			var action string
+			switch {
+			case f.dynamic:
+				action = function
+			case f.complete == completeFile:
+				action = "_files"
+			case f.complete == completeDir:
+				action = "_files -/"
+			}
+			spec += ":" + zshEscape(f.placeholder) + ":" + action
+		}
+		fmt.Fprintf(&b, "  %s \\\n", shellQuote(spec))
+	}
+	b.WriteString("  '*::argument:_files'\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteFishCompletion writes a fish completion script to w. See
+// WriteBashCompletion for details on the completions.
+func WriteFishCompletion(w io.Writer, flagset *flag.FlagSet, program string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s\n", program)
+	for _, f := range completionFlags(flagset) {
+		fmt.Fprintf(&b, "complete -c %s -o %s", shellQuote(program), shellQuote(f.name))
+		if !f.isBool {
+			switch {
+			case f.dynamic:
+				fmt.Fprintf(&b, " -x -a %s", shellQuote("("+program+" "+completeCommand+" (commandline -opc)[2..-1] (commandline -ct))"))
+			case f.complete == completeFile:
+				b.WriteString(" -r -F")
+			case f.complete == completeDir:
+				b.WriteString(" -x -a '(__fish_complete_directories (commandline -ct))'")
+			default:
+				b.WriteString(" -x")
+			}
+		}
+		if f.usage != "" {
+			fmt.Fprintf(&b, " -d %s", shellQuote(f.usage))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+const (
+	// completeFile indicates that values should be completed as file paths.
+	completeFile = "file"
+	// completeDir indicates that values should be completed as directory
+	// paths.
+	completeDir = "dir"
+)
+
+// completionFlag contains the information on a flag that is needed for
+// completion.
+type completionFlag struct {
+	name        string
+	usage       string
+	placeholder string
+	isBool      bool
+	complete    string
+	// dynamic indicates that completion of the flag's value is delegated to
+	// the program.
+	dynamic bool
+}
+
+// completionFlags returns the flags of the flag set that should be completed,
+// in the same order as they are shown in usage information.
+func completionFlags(flagset *flag.FlagSet) []completionFlag {
+	var state = lookupState(flagset)
+	var flags []completionFlag
+	for _, section := range usageSections(flagset) {
+		for _, f := range section.flags {
+			placeholder, usage := unquoteUsage(flagset, f)
+			if placeholder == "" {
+				placeholder = "value"
+			}
+			var cf = completionFlag{name: f.Name, usage: firstLine(usage), placeholder: placeholder, isBool: isBoolFlag(f.Value)}
+			if record := state.record(f.Name); record != nil {
+				cf.complete = record.tag.Options.Complete
+				cf.dynamic = state.completion && completerOf(record.value) != nil
+			} else if state.fileRecord(f.Name) != nil {
+				cf.complete = completeFile
+			}
+			flags = append(flags, cf)
+		}
+	}
+	return flags
+}
+
+// firstLine returns the first line of the text.
+func firstLine(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+// shellIdentifier converts the program name into a valid shell identifier.
+func shellIdentifier(program string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, program)
+}
+
+// shellQuote quotes the text with single quotes for use in shell scripts.
+func shellQuote(text string) string {
+	return "'" + strings.Replace(text, "'", `'\''`, -1) + "'"
+}
+
+// zshEscape escapes the characters that have special meaning in the
+// specifications of zsh's _arguments function.
+func zshEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(text)
+}
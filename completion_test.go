@@ -0,0 +1,192 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newCompletionFlagset(t *testing.T) *flag.FlagSet {
+	var s = struct {
+		Verbose bool   `flag:"verbose,false,Verbose output."`
+		Config  string `flag:"config,,Read configuration from {FILE}." flagopt:"complete=file"`
+		Output  string `flag:"output,,Write output to directory." flagopt:"complete=dir"`
+		Name    string `flag:"name,,The user's name [optional]." flagopt:"was=username"`
+		Debug   bool   `flag:"debug,false,Debugging." flagopt:"hidden"`
+	}{}
+	fs := flag.NewFlagSet("program", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	return fs
+}
+
+func TestWriteBashCompletion(t *testing.T) {
+	var b strings.Builder
+	if err := WriteCompletion(&b, newCompletionFlagset(t), "bash", "my-program"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	script := b.String()
+	for _, expected := range []string{
+		"_my_program_completion() {\n",
+		"        -config|--config)\n            COMPREPLY=($(compgen -f -- \"$cur\"))\n",
+		"        -output|--output)\n            COMPREPLY=($(compgen -d -- \"$cur\"))\n",
+		"        -name|--name)\n            return 0\n",
+		"compgen -W '-verbose -config -output -name' -- \"$cur\"",
+		"complete -F _my_program_completion 'my-program'\n",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("Expected script to contain %q:\n%s", expected, script)
+		}
+	}
+	if strings.Contains(script, "debug") || strings.Contains(script, "username") {
+		t.Error("Expected hidden flags and former names to be excluded:\n" + script)
+	}
+	if bash, err := exec.LookPath("bash"); err == nil {
+		if out, err := exec.Command(bash, "-n", "-c", script).CombinedOutput(); err != nil {
+			t.Error("Invalid bash script:", err, string(out))
+		}
+	}
+}
+
+func TestWriteZshCompletion(t *testing.T) {
+	var b strings.Builder
+	if err := WriteCompletion(&b, newCompletionFlagset(t), "zsh", "program"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := `#compdef program
+
+_arguments \
+  '-verbose[Verbose output.]' \
+  '-config[Read configuration from FILE.]:FILE:_files' \
+  '-output[Write output to directory.]:string:_files -/' \
+  '-name[The user'\''s name \[optional\].]:string:' \
+  '*::argument:_files'
+`
+	if b.String() != expected {
+		t.Fatal("Unexpected zsh completion script:\n" + b.String())
+	}
+}
+
+func TestWriteFishCompletion(t *testing.T) {
+	var b strings.Builder
+	if err := WriteCompletion(&b, newCompletionFlagset(t), "fish", "program"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := `# fish completion for program
+complete -c 'program' -o 'verbose' -d 'Verbose output.'
+complete -c 'program' -o 'config' -r -F -d 'Read configuration from FILE.'
+complete -c 'program' -o 'output' -x -a '(__fish_complete_directories (commandline -ct))' -d 'Write output to directory.'
+complete -c 'program' -o 'name' -x -d 'The user'\''s name [optional].'
+`
+	if b.String() != expected {
+		t.Fatal("Unexpected fish completion script:\n" + b.String())
+	}
+}
+
+func TestWriteCompletionUnsupportedShell(t *testing.T) {
+	var b strings.Builder
+	if err := WriteCompletion(&b, newCompletionFlagset(t), "powershell", "program"); err == nil {
+		t.Fatal("Expected error because of unsupported shell.")
+	}
+}
+
+type clusterName string
+
+func (c *clusterName) Complete(prefix string) []string {
+	var candidates []string
+	for _, name := range []string{"production", "preview", "staging"} {
+		if strings.HasPrefix(name, prefix) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+func newDynamicCompletionFlagset(t *testing.T) *flag.FlagSet {
+	var s = struct {
+		Cluster clusterName `flag:"cluster,,Name of the cluster."`
+		Verbose bool        `flag:"verbose,false,Verbose output."`
+		Name    string      `flag:"name,,The name."`
+	}{}
+	fs := flag.NewFlagSet("dynamic", flag.ContinueOnError)
+	EnableCompletion(fs)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	return fs
+}
+
+func TestComplete(t *testing.T) {
+	fs := newDynamicCompletionFlagset(t)
+	var testset = []struct {
+		args       []string
+		candidates []string
+	}{
+		{nil, nil},
+		{[]string{"-"}, []string{"-cluster", "-verbose", "-name"}},
+		{[]string{"-ver"}, []string{"-verbose"}},
+		{[]string{"--n"}, []string{"--name"}},
+		{[]string{"-cluster", "p"}, []string{"production", "preview"}},
+		{[]string{"-verbose", "-cluster", ""}, []string{"production", "preview", "staging"}},
+		{[]string{"-cluster=s"}, []string{"-cluster=staging"}},
+		{[]string{"-name", "p"}, nil},
+		{[]string{"-name", "p", "-c"}, []string{"-cluster"}},
+		{[]string{"argument", "-c"}, nil},
+	}
+	for nr, test := range testset {
+		if candidates := Complete(fs, test.args); !reflect.DeepEqual(candidates, test.candidates) {
+			t.Errorf("Test entry %d failed: %q", nr, candidates)
+		}
+	}
+}
+
+func TestParseCompleteCommand(t *testing.T) {
+	var s = struct {
+		Cluster clusterName `flag:"cluster,,Name of the cluster."`
+	}{}
+	var output bytes.Buffer
+	var exitCode = -1
+	defer func(original func(int), originalStdout io.Writer) {
+		exit, stdout = original, originalStdout
+	}(exit, stdout)
+	exit = func(code int) { exitCode = code }
+	stdout = &output
+	fs := flag.NewFlagSet("completecommand", flag.ContinueOnError)
+	EnableCompletion(fs)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"__complete", "-cluster", "pr"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if exitCode != 0 || output.String() != "production\npreview\n" {
+		t.Fatal("Unexpected completion result:", exitCode, output.String())
+	}
+}
+
+func TestWriteCompletionDynamic(t *testing.T) {
+	fs := newDynamicCompletionFlagset(t)
+	var b strings.Builder
+	if err := WriteBashCompletion(&b, fs, "program"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !strings.Contains(b.String(), "        -cluster|--cluster)\n            mapfile -t COMPREPLY < <(\"${COMP_WORDS[0]}\" __complete \"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null)\n") {
+		t.Error("Expected bash script to delegate completion of cluster:\n" + b.String())
+	}
+	b.Reset()
+	if err := WriteZshCompletion(&b, fs, "program"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !strings.Contains(b.String(), "_program_dynamic() {\n") || !strings.Contains(b.String(), "'-cluster[Name of the cluster.]:string:_program_dynamic'") {
+		t.Error("Expected zsh script to delegate completion of cluster:\n" + b.String())
+	}
+	b.Reset()
+	if err := WriteFishCompletion(&b, fs, "program"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !strings.Contains(b.String(), "complete -c 'program' -o 'cluster' -x -a '(program __complete (commandline -opc)[2..-1] (commandline -ct))'") {
+		t.Error("Expected fish script to delegate completion of cluster:\n" + b.String())
+	}
+}
@@ -6,8 +6,11 @@ package flagtag
 import (
 	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -75,8 +78,7 @@ func ConfigureAndParse(config interface{}) error {
 	if err := Configure(config); err != nil {
 		return err
 	}
-	flag.Parse()
-	return nil
+	return parse(flag.CommandLine, os.Args[1:])
 }
 
 // ConfigureFlagsetAndParse is like ConfigureAndParse with the addition that it
@@ -98,9 +100,86 @@ func ConfigureFlagsetAndParseArgs(config interface{}, flagset *flag.FlagSet, arg
 	if err := ConfigureFlagset(config, flagset); err != nil {
 		return err
 	}
-	return flagset.Parse(args)
+	return parse(flagset, args)
 }
 
+// parse parses the provided arguments using the flag set. Before parsing, the
+// arguments are preprocessed according to the flagtag settings of the flag
+// set.
+func parse(flagset *flag.FlagSet, args []string) error {
+	state := lookupState(flagset)
+	if state.completion && len(args) > 0 && args[0] == completeCommand {
+		for _, candidate := range Complete(flagset, args[1:]) {
+			fmt.Fprintln(stdout, candidate)
+		}
+		exit(0)
+		return nil
+	}
+	var origins []argOrigin
+	if state.responseFiles {
+		expanded, expandedOrigins, err := expandResponseFiles(args, isInputFlagArg(flagset))
+		if err != nil {
+			return failParse(flagset, err)
+		}
+		args, origins = expanded, expandedOrigins
+	}
+	if state.abbreviations {
+		expanded, err := expandAbbreviations(flagset, args)
+		if err != nil {
+			return failParse(flagset, err)
+		}
+		args = expanded
+	}
+	if err := checkUnknownFlags(flagset, args); err != nil {
+		return failParse(flagset, err)
+	}
+	state.resetSources()
+	state.resetStdin()
+	if err := flagset.Parse(args); err != nil {
+		return err
+	}
+	recordSources(flagset, args, origins)
+	if err := readFileEnvironment(flagset); err != nil {
+		return failParse(flagset, err)
+	}
+	return nil
+}
+
+// failParse handles an error that occurred while preprocessing arguments in
+// the same way as the flag set handles its own parse errors: the error is
+// printed to the flag set's output followed by usage information, after which
+// the flag set's error handling policy is applied.
+func failParse(flagset *flag.FlagSet, err error) error {
+	fmt.Fprintln(flagset.Output(), err)
+	if flagset.Usage != nil {
+		flagset.Usage()
+	} else {
+		fmt.Fprintf(flagset.Output(), "Usage of %s:\n", flagset.Name())
+		flagset.PrintDefaults()
+	}
+	switch flagset.ErrorHandling() {
+	case flag.ExitOnError:
+		exit(2)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// exit terminates the program. It is a variable such that it can be replaced
+// during testing.
+var exit = os.Exit
+
+// stdout is the destination for output that is part of the program's regular
+// output, such as completion candidates. It is a variable such that it can be
+// replaced during testing.
+var stdout io.Writer = os.Stdout
+
+// stdin is the source of values of flags with the 'input' flag option that are
+// read from standard input. It is a variable such that it can be replaced
+// during testing.
+var stdin io.Reader = os.Stdin
+
 // Configure will configure the flag parameters according to the tags of the
 // provided data type. It is allowed to call this method multiple times with
 // different data types. (As long as flag's Parse() method has not been called
@@ -132,17 +211,18 @@ func ConfigureFlagset(config interface{}, flagset *flag.FlagSet) error {
 	if err != nil {
 		return err
 	}
-	return configure(val, flagset)
+	return configure(val, flagset, scope{})
 }
 
 // configure (recursively) configures flags as they are discovered in the provided type and value.
+// The scope describes the position of the struct value within the config value.
 // In case of an error, the error is returned. Possible errors are:
 // - Invalid default values, error of type ErrInvalidDefault.
 // - nil pointer provided.
 // - nil interface provided.
 // - interface to nil value provided.
 // - Tagged variable uses unsupported data type.
-func configure(structValue reflect.Value, flagset *flag.FlagSet) error {
+func configure(structValue reflect.Value, flagset *flag.FlagSet, scope scope) error {
 	if flagset == nil {
 		return errors.New("flagset cannot be nil")
 	}
@@ -156,7 +236,11 @@ func configure(structValue reflect.Value, flagset *flag.FlagSet) error {
 			// if field is not tagged then we do not need to flag the type itself
 			if fieldType.Kind() == reflect.Struct {
 				// kind is a struct => recurse into inner struct
-				if err := configure(fieldValue, flagset); err != nil {
+				var inner = scope.enter(field)
+				if opts := parseOptions(field.Tag.Get("flagopt")); opts.Group != "" {
+					inner.group = opts.Group
+				}
+				if err := configure(fieldValue, flagset, inner); err != nil {
 					return err
 				}
 			}
@@ -167,12 +251,26 @@ func configure(structValue reflect.Value, flagset *flag.FlagSet) error {
 				// tag is invalid, since there is no name
 				return errors.New("field '" + field.Name + "': invalid flag name: empty string")
 			}
+			var optional bool
 			switch fieldType.Kind() {
 			case reflect.Ptr:
-				// unwrap pointer
+				if fieldType == locationType {
+					// time zones are used by pointer, nil is a valid value
+					break
+				}
 				if fieldValue.IsNil() {
-					return errors.New("field '" + field.Name + "' (tag '" + tag.Name + "'): cannot use nil pointer")
+					if !fieldValue.CanSet() {
+						return errors.New("field '" + field.Name + "' (tag '" + tag.Name + "') is unexported or unaddressable: cannot use this field")
+					}
+					if tag.DefaultValue == "" {
+						// optional value: allocated only once the flag is set
+						optional = true
+						break
+					}
+					// allocate value such that the default value can be assigned
+					fieldValue.Set(reflect.New(fieldType.Elem()))
 				}
+				// unwrap pointer
 				fieldType = fieldType.Elem()
 				fieldValue = fieldValue.Elem()
 			case reflect.Interface:
@@ -191,18 +289,50 @@ func configure(structValue reflect.Value, flagset *flag.FlagSet) error {
 			if !fieldValue.CanSet() {
 				return errors.New("field '" + field.Name + "' (tag '" + tag.Name + "') is unexported or unaddressable: cannot use this field")
 			}
-			if !tag.Options.SkipFlagValue && registerFlagByValueInterface(fieldValue, &tag, flagset) {
-				// no error during registration => Var-flag registered => continue with next field
-				continue
-			}
-			if err := registerFlagByPrimitive(field.Name, fieldValue, &tag, flagset); err != nil {
+			if optional {
+				value, err := registerOptionalFlag(field.Name, fieldValue, &tag, flagset)
+				if err != nil {
+					return err
+				}
+				fieldValue = value
+			} else if err := registerFlag(field.Name, fieldValue, &tag, flagset); err != nil {
 				return err
 			}
+			var record = &flagRecord{name: tag.Name, field: scope.path + field.Name, group: scope.group, parents: scope.parents, tag: tag, typ: field.Type, declared: structValue.Field(i), value: fieldValue}
+			if tag.Options.Group != "" {
+				record.group = tag.Options.Group
+			}
+			redactFlagValue(flagset, record)
+			registerAliases(flagset, record)
+			wrapFlagValue(flagset, record)
+			registerFileFlag(flagset, record)
+			stateOf(flagset).add(record)
 		}
 	}
 	return nil
 }
 
+// scope describes the position of a (nested) struct value within the config value.
+type scope struct {
+	// path is the prefix for the paths of the struct's fields, e.g. 'Inner.'.
+	path string
+	// group is the name of the group that flags in the struct belong to, unless overridden by a
+	// 'group' flag option.
+	group string
+	// parents contains the names of the enclosing struct fields, excluding embedded fields.
+	parents []string
+}
+
+// enter returns the scope for the nested struct value of the provided field.
+func (s scope) enter(field reflect.StructField) scope {
+	var inner = scope{path: s.path + field.Name + ".", group: s.group, parents: s.parents}
+	if !field.Anonymous {
+		inner.group = field.Name
+		inner.parents = append(append([]string(nil), s.parents...), field.Name)
+	}
+	return inner
+}
+
 // registerFlagByValueInterface checks if the provided type can be treated as flag.Value.
 // If so, a flag.Value flag is set and true is returned. If no flag is set, false is returned.
 func registerFlagByValueInterface(fieldValue reflect.Value, tag *flagTag, flagset *flag.FlagSet) bool {
@@ -229,6 +359,28 @@ func registerFlagByValueInterface(fieldValue reflect.Value, tag *flagTag, flagse
 	return true
 }
 
+// registerFlag registers a flag for the field, according to the field's type
+// and the flag options.
+func registerFlag(fieldName string, fieldValue reflect.Value, tag *flagTag, flagset *flag.FlagSet) error {
+	if tag.Options.Input {
+		return registerInputFlag(fieldName, fieldValue, tag, flagset)
+	}
+	if tag.Options.Unit != "" {
+		return registerSizeFlag(fieldName, fieldValue, tag, flagset)
+	}
+	if !tag.Options.SkipFlagValue && registerFlagByValueInterface(fieldValue, tag, flagset) {
+		return nil
+	}
+	// Check exact types first, since net.IP is a byte slice as well.
+	if ok, err := registerTextFlag(fieldName, fieldValue, tag, flagset); ok {
+		return err
+	}
+	if isByteSlice(fieldValue.Type()) {
+		return registerBytesFlag(fieldName, fieldValue, tag, flagset)
+	}
+	return registerFlagByPrimitive(fieldName, fieldValue, tag, flagset)
+}
+
 // registerFlagByPrimitive registers a single field as one of the primitive flag types. Types are matched by
 // kind, so types derived from one of the basic types are still eligible for a flag.
 //
@@ -317,13 +469,75 @@ func parseTag(value string, optvalue string) flagTag {
 	for len(parts) < 3 {
 		parts = append(parts, "")
 	}
-	var flag = flagTag{Name: parts[0], DefaultValue: parts[1], Description: parts[2]}
-	if optvalue != "" {
-		if strings.Contains(optvalue, "skipFlagValue") {
-			flag.Options.SkipFlagValue = true
+	return flagTag{Name: parts[0], DefaultValue: parts[1], Description: quotePlaceholder(parts[2]), Options: parseOptions(optvalue)}
+}
+
+// placeholderPattern matches a value placeholder in a usage description, e.g. '{addr}'.
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z0-9_.-]+)\}`)
+
+// quotePlaceholder converts the first value placeholder in the usage description, e.g. '{addr}', into
+// the back-quoted form that the flag package recognizes as the name of the flag's value. Descriptions
+// that already contain a back-quote are left untouched.
+func quotePlaceholder(description string) string {
+	if strings.Contains(description, "`") {
+		return description
+	}
+	var loc = placeholderPattern.FindStringSubmatchIndex(description)
+	if loc == nil {
+		return description
+	}
+	return description[:loc[0]] + "`" + description[loc[2]:loc[3]] + "`" + description[loc[1]:]
+}
+
+// parseOptions parses the comma-separated options of the 'flagopt'-tag. An option is either a name or a
+// name followed by '=' and a value. Unknown options are ignored.
+func parseOptions(optvalue string) Options {
+	var options Options
+	for _, option := range strings.Split(optvalue, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch name {
+		case "skipFlagValue":
+			options.SkipFlagValue = true
+		case "group":
+			options.Group = value
+		case "metavar":
+			options.Metavar = value
+		case "hidden":
+			options.Hidden = true
+		case "complete":
+			options.Complete = value
+		case "secret":
+			options.Secret = true
+		case "file":
+			options.File = true
+		case "input":
+			options.Input = true
+		case "layout":
+			options.Layout = value
+		case "unit":
+			options.Unit = value
+		case "hostport":
+			options.HostPort = true
+		case "encoding":
+			options.Encoding = value
+		case "len":
+			length, err := strconv.Atoi(value)
+			if err != nil || length < 0 {
+				length = -1
+			}
+			options.Len = length
+		case "was":
+			if value != "" {
+				options.Was = append(options.Was, value)
+			}
+		case "deprecated":
+			options.Deprecated = value
+			if options.Deprecated == "" {
+				options.Deprecated = "no longer supported"
+			}
 		}
 	}
-	return flag
+	return options
 }
 
 // flagTag contains the parsed tag values.
@@ -331,9 +545,50 @@ type flagTag struct {
 	Name         string
 	DefaultValue string
 	Description  string
-	Options      struct {
-		SkipFlagValue bool
-	}
+	Options      Options
+}
+
+// Options contains the flag options as specified with the 'flagopt' tag.
+type Options struct {
+	// SkipFlagValue indicates that the flag.Value implementation of the field is ignored.
+	SkipFlagValue bool
+	// Group is the name of the group under which the flag is shown in usage information.
+	Group string
+	// Metavar is the placeholder for the flag's value in usage information.
+	Metavar string
+	// Hidden indicates that the flag is omitted from usage information printed
+	// by PrintDefaults and Usage, and is not a candidate for abbreviations and
+	// suggestions.
+	Hidden bool
+	// Deprecated is the deprecation message, or empty if the flag is not deprecated.
+	Deprecated string
+	// Was contains the former names of the flag.
+	Was []string
+	// Complete is the kind of path ('file' or 'dir') the flag's value is completed as.
+	Complete string
+	// Secret indicates that the flag's value is redacted wherever it is shown.
+	Secret bool
+	// File indicates that the flag's value can be read from a file, using a
+	// companion flag '-<name>-file' or the '<NAME>_FILE' environment variable.
+	File bool
+	// Input indicates that the flag's value can be read from a file, as in
+	// '@path', or from standard input, as in '-'.
+	Input bool
+	// Layout is the layout of the value of a time.Time field: 'rfc3339' (the
+	// default), 'date', 'unix' or a layout as accepted by time.Parse.
+	Layout string
+	// Unit is the unit of the value of an integer field. The only supported
+	// unit is 'bytes'.
+	Unit string
+	// HostPort indicates that the value of a string field must be of the form
+	// 'host:port'.
+	HostPort bool
+	// Encoding is the encoding of the value of a []byte field: 'raw' (the
+	// default), 'hex', 'base64' or 'base64url'.
+	Encoding string
+	// Len is the required number of bytes of the value of a []byte field, or
+	// 0 if any length is accepted. It is -1 if the option's value is invalid.
+	Len int
 }
 
 // ErrInvalidDefault is an error type for the case of invalid defaults.
@@ -2,6 +2,7 @@ package flagtag
 
 import (
 	"flag"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -480,9 +481,18 @@ func TestRegisterValueInterfaceFlagNilPointer(t *testing.T) {
 	var s = struct {
 		D *dummyInt `flag:"flagValueDummyIntNilPointer,,My first flag.Value implementation."`
 	}{}
-	err := Configure(&s)
-	if err == nil {
-		t.Fatal("Expected an error since the pointer is nil, but didn't get anything.")
+	var flagset = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, flagset); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if s.D != nil {
+		t.Fatal("Expected nil pointer to stay nil until the flag is set.")
+	}
+	if err := flagset.Parse([]string{"-flagValueDummyIntNilPointer=5"}); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if s.D == nil || *s.D != 5 {
+		t.Fatal("Expected value to be allocated and set.")
 	}
 }
 
@@ -507,9 +517,65 @@ func TestRegisterPrimitiveFlagNilPointer(t *testing.T) {
 	var s = struct {
 		D *int `flag:"flagValueIntPointer,123,My first primitive pointer flag."`
 	}{}
-	err := Configure(&s)
-	if err == nil {
-		t.Fatal("Expected an error but got nothing.")
+	var flagset = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, flagset); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if s.D == nil || *s.D != 123 {
+		t.Fatal("Expected nil pointer with default value to be allocated.")
+	}
+}
+
+func TestRegisterPrimitiveFlagOptionalPointer(t *testing.T) {
+	var s = struct {
+		I *int    `flag:"i,,Optional integer."`
+		S *string `flag:"s,,Optional string."`
+		B *bool   `flag:"b,,Optional bool."`
+	}{}
+	var flagset = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, flagset); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if err := flagset.Parse([]string{"-i", "42", "-b"}); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if s.I == nil || *s.I != 42 {
+		t.Error("Expected integer to be allocated and set.")
+	}
+	if s.B == nil || !*s.B {
+		t.Error("Expected bool to be allocated and set without value.")
+	}
+	if s.S != nil {
+		t.Error("Expected string to stay nil.")
+	}
+	if flagset.Lookup("i").DefValue != "" {
+		t.Error("Expected optional flag to have no default value.")
+	}
+}
+
+func TestRegisterPrimitiveFlagOptionalPointerInvalidValue(t *testing.T) {
+	var s = struct {
+		I *int `flag:"i,,Optional integer."`
+	}{}
+	var flagset = flag.NewFlagSet("test", flag.ContinueOnError)
+	flagset.SetOutput(io.Discard)
+	if err := ConfigureFlagset(&s, flagset); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if flagset.Parse([]string{"-i", "abc"}) == nil {
+		t.Fatal("Expected an error for an invalid value.")
+	}
+	if s.I != nil {
+		t.Fatal("Expected field to stay nil after an invalid value.")
+	}
+}
+
+func TestRegisterOptionalPointerUnsupportedType(t *testing.T) {
+	var s = struct {
+		C *complex64 `flag:"c,,Unsupported type."`
+	}{}
+	if ConfigureFlagset(&s, flag.NewFlagSet("test", flag.ContinueOnError)) == nil {
+		t.Fatal("Expected an error for an unsupported type.")
 	}
 }
 
@@ -598,9 +664,28 @@ func TestRegisterDurationNilPointer(t *testing.T) {
 	var s = struct {
 		D *time.Duration `flag:"flagDuration,1h,Specify duration"`
 	}{}
-	err := Configure(&s)
-	if err == nil {
-		t.Fatal("Expected an error because of nil pointer.")
+	var flagset = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, flagset); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if s.D == nil || *s.D != time.Hour {
+		t.Fatal("Expected nil pointer with default value to be allocated.")
+	}
+}
+
+func TestRegisterDurationOptionalPointer(t *testing.T) {
+	var s = struct {
+		D *time.Duration `flag:"d,,Specify duration"`
+	}{}
+	var flagset = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, flagset); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if err := flagset.Parse([]string{"-d", "2m"}); err != nil {
+		t.Fatal("Unexpected error: " + err.Error())
+	}
+	if s.D == nil || *s.D != 2*time.Minute {
+		t.Fatal("Expected duration to be allocated and set.")
 	}
 }
 
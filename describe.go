@@ -0,0 +1,137 @@
+package flagtag
+
+import (
+	"flag"
+	"reflect"
+	"strconv"
+)
+
+// Descriptor describes a flag that is configured by flagtag.
+type Descriptor struct {
+	// Name is the name of the flag.
+	Name string
+	// Aliases contains the former names of the flag, as specified with the
+	// 'was' flag option.
+	Aliases []string
+	// Field is the path of the struct field relative to the config value,
+	// e.g. 'Server.Port'.
+	Field string
+	// Type is the declared type of the struct field.
+	Type reflect.Type
+	// Default is the default value of the flag, as represented by the flag's
+	// value.
+	Default string
+	// Usage is the usage description of the flag.
+	Usage string
+	// Group is the name of the group the flag belongs to, or empty if the
+	// flag does not belong to a group.
+	Group string
+	// Options contains the flag options.
+	Options Options
+	// Source identifies where the flag's current value came from.
+	Source Source
+}
+
+// Source identifies where the value of a flag came from.
+type Source struct {
+	// Kind is the kind of source.
+	Kind SourceKind
+	// Name is the name of the file or environment variable that the value
+	// came from, if applicable.
+	Name string
+	// Line is the line number in the file, if applicable.
+	Line int
+}
+
+// String returns a description of the source.
+func (s Source) String() string {
+	switch {
+	case s.Name != "" && s.Line > 0:
+		return s.Kind.String() + " " + s.Name + ":" + strconv.Itoa(s.Line)
+	case s.Name != "":
+		return s.Kind.String() + " " + s.Name
+	default:
+		return s.Kind.String()
+	}
+}
+
+// SourceKind is the kind of source of a flag's value.
+type SourceKind int
+
+const (
+	// SourceDefault indicates that the flag has its default value.
+	SourceDefault SourceKind = iota
+	// SourceCommandLine indicates that the value was set on the command line.
+	SourceCommandLine
+	// SourceResponseFile indicates that the value was set in a response file
+	// that was referenced on the command line.
+	SourceResponseFile
+	// SourceFile indicates that the value was read from a file that was
+	// specified on the command line, see the 'file' flag option.
+	SourceFile
+	// SourceEnvironment indicates that the value was read from a file that
+	// was specified by an environment variable, see the 'file' flag option.
+	SourceEnvironment
+)
+
+// String returns the name of the kind of source.
+func (k SourceKind) String() string {
+	switch k {
+	case SourceDefault:
+		return "default"
+	case SourceCommandLine:
+		return "command line"
+	case SourceResponseFile:
+		return "response file"
+	case SourceFile:
+		return "file"
+	case SourceEnvironment:
+		return "environment"
+	default:
+		return "unknown"
+	}
+}
+
+// Describe returns descriptors for all flags configured by flagtag in the flag
+// set, in order of declaration.
+func Describe(flagset *flag.FlagSet) []Descriptor {
+	var descriptors []Descriptor
+	for _, record := range lookupState(flagset).records() {
+		var f = flagset.Lookup(record.name)
+		if f == nil {
+			continue
+		}
+		var options = record.tag.Options
+		options.Was = append([]string(nil), options.Was...)
+		_, usage := unquoteUsage(flagset, f)
+		descriptors = append(descriptors, Descriptor{
+			Name:    record.name,
+			Aliases: append([]string(nil), record.tag.Options.Was...),
+			Field:   record.field,
+			Type:    record.typ,
+			Default: f.DefValue,
+			Usage:   usage,
+			Group:   record.group,
+			Options: options,
+			Source:  sourceOf(flagset, record),
+		})
+	}
+	return descriptors
+}
+
+// sourceOf determines the source of the flag's current value. If flagtag has
+// not recorded a source, the flag is considered to be set on the command line
+// if it was set through the flag set.
+func sourceOf(flagset *flag.FlagSet, record *flagRecord) Source {
+	var state = lookupState(flagset)
+	var source = state.recordedSource(record)
+	if source.Kind != SourceDefault {
+		return source
+	}
+	flagset.Visit(func(f *flag.Flag) {
+		if f.Name == record.name || state.alias(f.Name) == record {
+			source.Kind = SourceCommandLine
+		}
+	})
+	return source
+}
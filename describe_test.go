@@ -0,0 +1,67 @@
+package flagtag
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	var s = struct {
+		Verbose bool `flag:"verbose,false,Enable verbose output."`
+		Server  struct {
+			Port    *int          `flag:"port,8080,Listen on {PORT}."`
+			Timeout time.Duration `flag:"timeout,5s,The timeout." flagopt:"was=deadline,hidden"`
+		}
+	}{}
+	s.Server.Port = new(int)
+	fs := flag.NewFlagSet("describe", flag.ContinueOnError)
+	SetWarningOutput(fs, io.Discard)
+	fs.Int("manual", 0, "Not configured by flagtag.")
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-verbose", "-deadline", "1s"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var expected = []Descriptor{
+		{
+			Name:    "verbose",
+			Field:   "Verbose",
+			Type:    reflect.TypeOf(false),
+			Default: "false",
+			Usage:   "Enable verbose output.",
+			Source:  Source{Kind: SourceCommandLine},
+		},
+		{
+			Name:    "port",
+			Field:   "Server.Port",
+			Type:    reflect.TypeOf((*int)(nil)),
+			Default: "8080",
+			Usage:   "Listen on PORT.",
+			Group:   "Server",
+		},
+		{
+			Name:    "timeout",
+			Aliases: []string{"deadline"},
+			Field:   "Server.Timeout",
+			Type:    reflect.TypeOf(time.Duration(0)),
+			Default: "5s",
+			Usage:   "The timeout.",
+			Group:   "Server",
+			Options: Options{Hidden: true, Was: []string{"deadline"}},
+			Source:  Source{Kind: SourceCommandLine},
+		},
+	}
+	if descriptors := Describe(fs); !reflect.DeepEqual(descriptors, expected) {
+		t.Fatalf("Unexpected descriptors:\n%+v", descriptors)
+	}
+}
+
+func TestSourceString(t *testing.T) {
+	if s := (Source{Kind: SourceDefault}).String(); s != "default" {
+		t.Error("Unexpected description:", s)
+	}
+	if s := (Source{Kind: SourceCommandLine}).String(); s != "command line" {
+		t.Error("Unexpected description:", s)
+	}
+}
@@ -1,8 +1,10 @@
 package flagtag
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
 	"time"
 )
 
@@ -38,3 +40,42 @@ func ExampleMustConfigureAndParse_sleep() {
 		log.Println("Done.")
 	}
 }
+
+func ExampleUsage() {
+	var config struct {
+		Verbose bool `flag:"verbose,false,Verbose output."`
+		Server  struct {
+			Listen string `flag:"listen,:8080,Address to listen on."`
+		}
+	}
+	flagset := flag.NewFlagSet("server", flag.ExitOnError)
+	flagset.SetOutput(os.Stdout)
+	flagset.Usage = Usage(flagset)
+	MustConfigureFlagset(&config, flagset)
+	flagset.Usage()
+	// Output:
+	// Usage of server:
+	//   -verbose
+	//         Verbose output.
+	//
+	// Server:
+	//   -listen string
+	//         Address to listen on. (default ":8080")
+}
+
+func ExampleWriteSources() {
+	var config struct {
+		Listen      string `flag:"listen,:8080,Address to listen on."`
+		Verbose     bool   `flag:"verbose,false,Verbose output."`
+		PrintConfig bool   `flag:"print-config,false,Print the effective configuration and exit."`
+	}
+	flagset := flag.NewFlagSet("server", flag.ExitOnError)
+	MustConfigureFlagsetAndParseArgs(&config, flagset, []string{"-verbose", "-print-config"})
+	if config.PrintConfig {
+		WriteSources(os.Stdout, flagset)
+	}
+	// Output:
+	// -listen=:8080       default
+	// -verbose=true       command line
+	// -print-config=true  command line
+}
@@ -0,0 +1,161 @@
+package flagtag
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteConfig writes the current configuration of all flags configured by
+// flagtag in the flag set to w, in the provided format. The supported formats
+// are 'json', 'ini' and 'env'. If comments is true, the description of every
+// flag is included as a comment, for formats that support comments. See
+// WriteJSONConfig, WriteINIConfig and WriteEnvConfig for details on the
+// formats. Values of secret flags are redacted.
+func WriteConfig(w io.Writer, flagset *flag.FlagSet, format string, comments bool) error {
+	switch format {
+	case "json":
+		return WriteJSONConfig(w, flagset)
+	case "ini":
+		return WriteINIConfig(w, flagset, comments)
+	case "env":
+		return WriteEnvConfig(w, flagset, comments)
+	default:
+		return errors.New("unsupported configuration format '" + format + "'")
+	}
+}
+
+// WriteJSONConfig writes the current configuration as a JSON object to w. The
+// value of every flag is keyed by the flag's name. Flags declared in nested
+// structs are collected in nested objects, named after the struct fields, such
+// that the document matches the schema written by WriteJSONSchema. Optional
+// values that are not set and deprecated flags that have their default value
+// are omitted.
+func WriteJSONConfig(w io.Writer, flagset *flag.FlagSet) error {
+	var values = make(map[*flagRecord]string)
+	var records []*flagRecord
+	for _, v := range effectiveValues(flagset, true) {
+		values[v.record] = v.redactedValue()
+		records = append(records, v.record)
+	}
+	var config = nestRecords(flagset, records, func(record *flagRecord, f *flag.Flag) interface{} {
+		return jsonValue(f, values[record])
+	}, func(members jsonObject) interface{} {
+		return members
+	})
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteINIConfig writes the current configuration in INI format to w. The
+// value of every flag is keyed by the flag's name. Flags declared in nested
+// structs are written in a section named after the path of struct fields,
+// e.g. '[Server.TLS]'. Values are quoted if necessary. Optional values that are
+// not set and deprecated flags that have their default value are omitted.
+func WriteINIConfig(w io.Writer, flagset *flag.FlagSet, comments bool) error {
+	var sections []string
+	var entries = make(map[string][]effectiveValue)
+	for _, v := range effectiveValues(flagset, true) {
+		var section = strings.Join(v.record.parents, ".")
+		if _, ok := entries[section]; !ok && section != "" {
+			sections = append(sections, section)
+		}
+		entries[section] = append(entries[section], v)
+	}
+	var b strings.Builder
+	var writeEntries = func(values []effectiveValue) {
+		for _, v := range values {
+			if comments {
+				writeComments(&b, "; ", flagset, v.flag)
+			}
+			b.WriteString(v.flag.Name + " = " + iniValue(v.redactedValue()) + "\n")
+		}
+	}
+	writeEntries(entries[""])
+	for _, section := range sections {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("[" + section + "]\n")
+		writeEntries(entries[section])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteEnvConfig writes the current configuration in dotenv format to w. The
+// value of every flag is keyed by the name of the environment variable derived
+// from the flag's name: the name in upper case, with any character other than
+// letters and digits replaced by an underscore. Values are quoted if
+// necessary. Optional values that are not set and deprecated flags that have
+// their default value are omitted.
+func WriteEnvConfig(w io.Writer, flagset *flag.FlagSet, comments bool) error {
+	var b strings.Builder
+	for _, v := range effectiveValues(flagset, true) {
+		if comments {
+			writeComments(&b, "# ", flagset, v.flag)
+		}
+		b.WriteString(envName(v.flag.Name) + "=" + envValue(v.redactedValue()) + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeComments writes the flag's description as comment lines, each starting
+// with the provided prefix.
+func writeComments(b *strings.Builder, prefix string, flagset *flag.FlagSet, f *flag.Flag) {
+	_, usage := unquoteUsage(flagset, f)
+	if usage == "" {
+		return
+	}
+	for _, line := range strings.Split(usage, "\n") {
+		b.WriteString(strings.TrimRight(prefix+line, " ") + "\n")
+	}
+}
+
+// envName derives the name of an environment variable from a flag name.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z' || r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// iniValue quotes the value for use in an INI file if it would otherwise not
+// be read back as is.
+func iniValue(value string) string {
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, ";#\"\n\r") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// envValue quotes the value for use in a dotenv file if it contains any
+// characters other than letters, digits and a few safe punctuation characters.
+// Single quotes are preferred, since their content is not subject to variable
+// expansion.
+func envValue(value string) string {
+	var safe = strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./:,@+", r))
+	}) < 0
+	if safe {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`).Replace(value) + `"`
+}
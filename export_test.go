@@ -0,0 +1,116 @@
+package flagtag
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type exportConfig struct {
+	Name    string `flag:"name,some name,Name of the {thing}."`
+	Verbose bool   `flag:"verbose,false,Verbose output."`
+	Limit   *int   `flag:"limit,,Optional limit."`
+	Server  struct {
+		Listen  string        `flag:"listen-addr,:8080,Address to listen on."`
+		Timeout time.Duration `flag:"timeout,5s,"`
+	}
+	Ratio float64 `flag:"ratio,0.5,Ratio."`
+}
+
+func configureExport(t *testing.T, args []string) *flag.FlagSet {
+	var s exportConfig
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	SetWarningOutput(fs, io.Discard)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, args); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	return fs
+}
+
+func TestWriteJSONConfig(t *testing.T) {
+	fs := configureExport(t, []string{"-verbose", "-timeout=1m"})
+	var b strings.Builder
+	if err := WriteConfig(&b, fs, "json", true); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := `{
+  "name": "some name",
+  "verbose": true,
+  "Server": {
+    "listen-addr": ":8080",
+    "timeout": "1m0s"
+  },
+  "ratio": 0.5
+}
+`
+	if b.String() != expected {
+		t.Fatal("Unexpected JSON configuration:", b.String())
+	}
+}
+
+func TestWriteINIConfig(t *testing.T) {
+	fs := configureExport(t, []string{"-name", " padded ", "-limit", "3"})
+	var b strings.Builder
+	if err := WriteConfig(&b, fs, "ini", true); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := `; Name of the thing.
+name = " padded "
+; Verbose output.
+verbose = false
+; Optional limit.
+limit = 3
+; Ratio.
+ratio = 0.5
+
+[Server]
+; Address to listen on.
+listen-addr = :8080
+timeout = 5s
+`
+	if b.String() != expected {
+		t.Fatal("Unexpected INI configuration:", b.String())
+	}
+}
+
+func TestWriteEnvConfig(t *testing.T) {
+	fs := configureExport(t, []string{"-name", "it's $HOME"})
+	var b strings.Builder
+	if err := WriteConfig(&b, fs, "env", false); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := `NAME="it's \$HOME"
+VERBOSE=false
+LISTEN_ADDR=:8080
+TIMEOUT=5s
+RATIO=0.5
+`
+	if b.String() != expected {
+		t.Fatal("Unexpected dotenv configuration:", b.String())
+	}
+}
+
+func TestWriteConfigUnsupportedFormat(t *testing.T) {
+	if WriteConfig(io.Discard, flag.NewFlagSet("unsupported", flag.ContinueOnError), "yaml", false) == nil {
+		t.Fatal("Expected an error for an unsupported format.")
+	}
+}
+
+func TestEnvValue(t *testing.T) {
+	var testset = []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"simple-value_1.2", "simple-value_1.2"},
+		{"two words", "'two words'"},
+		{"a\nb", `"a\nb"`},
+	}
+	for _, test := range testset {
+		if v := envValue(test.value); v != test.expected {
+			t.Error("Unexpected quoting of", test.value, ":", v)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package flagtag
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// fileFlagName returns the name of the companion flag that reads the value of
+// the named flag from a file.
+func fileFlagName(name string) string {
+	return name + "-file"
+}
+
+// fileEnvName returns the name of the environment variable that specifies the
+// file to read the value of the named flag from.
+func fileEnvName(name string) string {
+	return envName(name) + "_FILE"
+}
+
+// registerFileFlag registers the companion flag '-<name>-file' for the flag of
+// the record, if the flag is marked with the 'file' flag option. Setting the
+// companion flag reads the file and sets the flag's value to its content.
+func registerFileFlag(flagset *flag.FlagSet, record *flagRecord) {
+	if !record.tag.Options.File {
+		return
+	}
+	var usage = "Read the value of -" + record.name + " from `file`, or from the file named by the " + fileEnvName(record.name) + " environment variable."
+	flagset.Var(&fileValue{flagset: flagset, name: record.name}, fileFlagName(record.name), usage)
+}
+
+// readFileEnvironment reads the values of flags marked with the 'file' flag
+// option from the files specified by their environment variables. Flags that
+// were set by the parsed arguments are left untouched.
+func readFileEnvironment(flagset *flag.FlagSet) error {
+	var state = lookupState(flagset)
+	for _, record := range state.records() {
+		if !record.tag.Options.File || sourceOf(flagset, record).Kind != SourceDefault {
+			continue
+		}
+		var env = fileEnvName(record.name)
+		var path = os.Getenv(env)
+		if path == "" {
+			continue
+		}
+		if err := setFromFile(flagset.Lookup(record.name), path); err != nil {
+			return fmt.Errorf("invalid value %q for environment variable %s: %v", path, env, err)
+		}
+		state.setSource(record, Source{Kind: SourceEnvironment, Name: env})
+	}
+	return nil
+}
+
+// setFromFile sets the flag's value to the content of the file. A single
+// trailing line break is removed from the content.
+func setFromFile(f *flag.Flag, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var content = strings.TrimSuffix(string(data), "\n")
+	content = strings.TrimSuffix(content, "\r")
+	return f.Value.Set(content)
+}
+
+// fileValue is the value of the companion flag that reads the value of the
+// named flag from a file.
+type fileValue struct {
+	flagset *flag.FlagSet
+	name    string
+	path    string
+}
+
+func (v *fileValue) String() string {
+	return v.path
+}
+
+func (v *fileValue) typeName() string {
+	return "string"
+}
+
+func (v *fileValue) Set(path string) error {
+	if err := setFromFile(v.flagset.Lookup(v.name), path); err != nil {
+		return err
+	}
+	v.path = path
+	return nil
+}
@@ -0,0 +1,114 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fileConfig struct {
+	Password string `flag:"db-password,,Password of the database." flagopt:"secret,file"`
+	User     string `flag:"db-user,admin,User of the database."`
+}
+
+func writeSecretFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal("Failed to write secret file:", err)
+	}
+	return path
+}
+
+func TestFileFlag(t *testing.T) {
+	var path = writeSecretFile(t, "s3cret\r\n")
+	var s fileConfig
+	fs := flag.NewFlagSet("file", flag.ContinueOnError)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-db-password-file", path}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.Password != "s3cret" {
+		t.Fatalf("Expected password to be read from file, but got %q", s.Password)
+	}
+	if source, _ := SourceOf(fs, "db-password"); source != (Source{Kind: SourceFile, Name: path}) {
+		t.Fatal("Unexpected source:", source)
+	}
+	if !IsSet(&s, &s.Password) {
+		t.Fatal("Expected password to be set.")
+	}
+}
+
+func TestFileFlagMissingFile(t *testing.T) {
+	var s fileConfig
+	fs := flag.NewFlagSet("filemissing", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if ConfigureFlagsetAndParseArgs(&s, fs, []string{"-db-password-file", filepath.Join(t.TempDir(), "missing")}) == nil {
+		t.Fatal("Expected an error for a missing file.")
+	}
+}
+
+func TestFileEnvironment(t *testing.T) {
+	var path = writeSecretFile(t, "from env\n\n")
+	t.Setenv("DB_PASSWORD_FILE", path)
+	var testset = []struct {
+		args     []string
+		expected string
+		source   Source
+	}{
+		{nil, "from env\n", Source{Kind: SourceEnvironment, Name: "DB_PASSWORD_FILE"}},
+		{[]string{"-db-password", "explicit"}, "explicit", Source{Kind: SourceCommandLine}},
+	}
+	for _, test := range testset {
+		var s fileConfig
+		fs := flag.NewFlagSet("fileenv", flag.ContinueOnError)
+		if err := ConfigureFlagsetAndParseArgs(&s, fs, test.args); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if s.Password != test.expected {
+			t.Errorf("Expected password %q, but got %q", test.expected, s.Password)
+		}
+		if source, _ := SourceOf(fs, "db-password"); source != test.source {
+			t.Error("Unexpected source:", source)
+		}
+	}
+}
+
+func TestFileEnvironmentMissingFile(t *testing.T) {
+	t.Setenv("DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	var s fileConfig
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("fileenvmissing", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	err := ConfigureFlagsetAndParseArgs(&s, fs, nil)
+	if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD_FILE") {
+		t.Fatal("Expected an error for the environment variable, but got", err)
+	}
+	if !strings.Contains(output.String(), "DB_PASSWORD_FILE") {
+		t.Fatal("Expected the error to be reported, but got", output.String())
+	}
+}
+
+func TestFileFlagUsage(t *testing.T) {
+	var s fileConfig
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("fileusage", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	writeDefaults(&output, fs, 80)
+	expected := `  -db-password string
+        Password of the database.
+  -db-password-file file
+        Read the value of -db-password from file, or from the file named by the
+        DB_PASSWORD_FILE environment variable.
+  -db-user string
+        User of the database. (default "admin")
+`
+	if output.String() != expected {
+		t.Fatal("Unexpected usage:", output.String())
+	}
+}
@@ -0,0 +1,81 @@
+package flagtag
+
+import (
+	"flag"
+	"reflect"
+)
+
+// FormatArgs formats the current values of all flags configured by flagtag as
+// command line arguments, in order of declaration. Every flag is formatted as
+// a single argument of the form '-name=value', such that the arguments can be
+// parsed again by the flag set, e.g. to start a child process with the same
+// configuration. If all is false, only flags whose value differs from the
+// default value are included. Optional values (nil pointer fields) that are
+// not set are omitted, and those that are set are always included, even if
+// set to the zero value. Deprecated flags that have their default value are
+// omitted.
+//
+// Values of secret flags are included in clear text, so the arguments must
+// not be logged or otherwise shown. Use FormatRedactedArgs for that purpose.
+func FormatArgs(flagset *flag.FlagSet, all bool) []string {
+	var args = []string{}
+	for _, v := range effectiveValues(flagset, all) {
+		args = append(args, "-"+v.flag.Name+"="+v.value)
+	}
+	return args
+}
+
+// FormatRedactedArgs formats the flags in the same way as FormatArgs, except
+// that values of secret flags are redacted. The arguments are safe to log, but
+// do not necessarily reproduce the configuration when parsed.
+func FormatRedactedArgs(flagset *flag.FlagSet, all bool) []string {
+	var args = []string{}
+	for _, v := range effectiveValues(flagset, all) {
+		args = append(args, "-"+v.flag.Name+"="+v.redactedValue())
+	}
+	return args
+}
+
+// effectiveValue is the current value of a flag configured by flagtag.
+type effectiveValue struct {
+	record *flagRecord
+	flag   *flag.Flag
+	// value is the flag's value in the form accepted by the flag's Set
+	// method.
+	value string
+}
+
+// redactedValue returns the value as it may be shown, i.e. redacted if the
+// flag is secret.
+func (v effectiveValue) redactedValue() string {
+	if v.value != "" && secretOf(v.flag.Value) != nil {
+		return redacted
+	}
+	return v.value
+}
+
+// effectiveValues returns the current values of the flags configured by
+// flagtag, in order of declaration. Optional values that are not set are
+// omitted, as are deprecated flags that have their default value. If all is
+// false, flags that have their default value are omitted altogether. Optional
+// values that are set are always included, since they differ from the default
+// even if they hold the zero value.
+func effectiveValues(flagset *flag.FlagSet, all bool) []effectiveValue {
+	var values []effectiveValue
+	for _, record := range lookupState(flagset).records() {
+		var f = flagset.Lookup(record.name)
+		if f == nil {
+			continue
+		}
+		if record.declared.Kind() == reflect.Ptr && record.declared.IsNil() {
+			// optional value that is not set
+			continue
+		}
+		var value = unwrapValue(f.Value).String()
+		if !isOptionalFlag(f.Value) && value == defaultValue(f) && (!all || record.tag.Options.Deprecated != "") {
+			continue
+		}
+		values = append(values, effectiveValue{record: record, flag: f, value: value})
+	}
+	return values
+}
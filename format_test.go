@@ -0,0 +1,78 @@
+package flagtag
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type formatConfig struct {
+	Name    string        `flag:"name,default,Name."`
+	Count   int           `flag:"count,1,Count."`
+	Verbose bool          `flag:"verbose,false,Verbose."`
+	Timeout time.Duration `flag:"timeout,1s,Timeout."`
+	Limit   *int          `flag:"limit,,Optional limit."`
+	Label   *string       `flag:"label,,Optional label."`
+	Old     string        `flag:"old,,Old flag." flagopt:"deprecated"`
+	Inner   struct {
+		Level uint `flag:"level,3,Level."`
+	}
+	Input string `flag:"input,,Input." flagopt:"input"`
+}
+
+func TestFormatArgs(t *testing.T) {
+	var s formatConfig
+	fs := flag.NewFlagSet("format", flag.ContinueOnError)
+	SetWarningOutput(fs, io.Discard)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-count", "2", "-verbose", "-name", "a b", "-limit=0", "-level=4"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var testset = []struct {
+		all      bool
+		expected []string
+	}{
+		{false, []string{"-name=a b", "-count=2", "-verbose=true", "-limit=0", "-level=4"}},
+		{true, []string{"-name=a b", "-count=2", "-verbose=true", "-timeout=1s", "-limit=0", "-level=4", "-input="}},
+	}
+	for _, test := range testset {
+		if args := FormatArgs(fs, test.all); !reflect.DeepEqual(args, test.expected) {
+			t.Error("Unexpected arguments for all =", test.all, ":", args)
+		}
+	}
+}
+
+func TestFormatArgsDefaults(t *testing.T) {
+	var s formatConfig
+	fs := flag.NewFlagSet("formatdefaults", flag.ContinueOnError)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, nil); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if args := FormatArgs(fs, false); len(args) != 0 {
+		t.Fatal("Expected no arguments, but got", args)
+	}
+}
+
+func TestFormatArgsRoundTrip(t *testing.T) {
+	var s formatConfig
+	fs := flag.NewFlagSet("roundtrip", flag.ContinueOnError)
+	SetWarningOutput(fs, io.Discard)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-name=", "-timeout=1m", "-old", "x", "-limit", "-5", "-label=", "-input", "@-"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for _, all := range []bool{false, true} {
+		var copied formatConfig
+		fs2 := flag.NewFlagSet("roundtrip2", flag.ContinueOnError)
+		SetWarningOutput(fs2, io.Discard)
+		if err := ConfigureFlagsetAndParseArgs(&copied, fs2, FormatArgs(fs, all)); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if !reflect.DeepEqual(s, copied) {
+			t.Error("Expected identical configuration after round trip, but got", copied)
+		}
+		if !IsSet(&copied, &copied.Label) {
+			t.Error("Expected optional label that was set to the zero value to be set after round trip.")
+		}
+	}
+}
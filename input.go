@@ -0,0 +1,99 @@
+package flagtag
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// registerInputFlag registers a flag for a string or []byte field with the
+// 'input' flag option. A value of the form '@path' is read from the file at
+// path, and the value '-' is read from standard input. A value starting with
+// '@@' is taken literally, with the first '@' removed, and the value '@-' is
+// the literal value '-'. The default value is always taken literally.
+func registerInputFlag(fieldName string, fieldValue reflect.Value, tag *flagTag, flagset *flag.FlagSet) error {
+	if fieldValue.Kind() != reflect.String && (fieldValue.Kind() != reflect.Slice || fieldValue.Type().Elem().Kind() != reflect.Uint8) {
+		return errors.New("field '" + fieldName + "' (tag '" + tag.Name + "'): flag option 'input' requires a string or []byte field")
+	}
+	if tag.Options.Encoding != "" && tag.Options.Encoding != encodingRaw {
+		return errors.New("field '" + fieldName + "' (tag '" + tag.Name + "'): flag option 'input' cannot be combined with an encoding")
+	}
+	var value = &inputValue{field: fieldValue, flagset: flagset, name: tag.Name}
+	if tag.DefaultValue != "" {
+		value.set([]byte(tag.DefaultValue))
+	}
+	flagset.Var(value, tag.Name, tag.Description)
+	return nil
+}
+
+// inputValue is the flag value of a field with the 'input' flag option.
+type inputValue struct {
+	field   reflect.Value
+	flagset *flag.FlagSet
+	name    string
+}
+
+func (v *inputValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	var value = v.content()
+	if strings.HasPrefix(value, "@") || value == "-" {
+		// escape value such that it is not read from a file or standard input
+		return "@" + value
+	}
+	return value
+}
+
+func (v *inputValue) Set(value string) error {
+	switch {
+	case strings.HasPrefix(value, "@@"), value == "@-":
+		v.set([]byte(value[1:]))
+	case strings.HasPrefix(value, "@"):
+		data, err := os.ReadFile(value[1:])
+		if err != nil {
+			return err
+		}
+		v.set(data)
+	case value == "-":
+		if err := stateOf(v.flagset).consumeStdin(v.name); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		v.set(data)
+	default:
+		v.set([]byte(value))
+	}
+	return nil
+}
+
+func (v *inputValue) typeName() string {
+	return "string"
+}
+
+func (v *inputValue) Get() interface{} {
+	return v.field.Interface()
+}
+
+// content returns the field's value as a string.
+func (v *inputValue) content() string {
+	if v.field.Kind() == reflect.String {
+		return v.field.String()
+	}
+	return string(v.field.Bytes())
+}
+
+// set sets the field's value to the data.
+func (v *inputValue) set(data []byte) {
+	if v.field.Kind() == reflect.String {
+		v.field.SetString(string(data))
+	} else {
+		v.field.SetBytes(data)
+	}
+}
@@ -0,0 +1,167 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type inputConfig struct {
+	Body    string `flag:"body,@default,Body of the request." flagopt:"input"`
+	Payload []byte `flag:"payload,,Payload of the request." flagopt:"input"`
+}
+
+func replaceStdin(t *testing.T, content string) {
+	var original = stdin
+	stdin = strings.NewReader(content)
+	t.Cleanup(func() { stdin = original })
+}
+
+func TestInputFlag(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "payload.json")
+	if err := os.WriteFile(path, []byte("{\"a\": 1}\n"), 0600); err != nil {
+		t.Fatal("Failed to write file:", err)
+	}
+	replaceStdin(t, "from stdin")
+	var testset = []struct {
+		args    []string
+		body    string
+		payload string
+	}{
+		{nil, "@default", ""},
+		{[]string{"-body", "literal", "-payload", "literal"}, "literal", "literal"},
+		{[]string{"-body", "@" + path}, "{\"a\": 1}\n", ""},
+		{[]string{"-payload=-"}, "@default", "from stdin"},
+		{[]string{"-body", "@@at", "-payload", "-value"}, "@at", "-value"},
+		{[]string{"-body", "@-", "-payload", "@@-"}, "-", "@-"},
+	}
+	for _, test := range testset {
+		var s inputConfig
+		fs := flag.NewFlagSet("input", flag.ContinueOnError)
+		if err := ConfigureFlagsetAndParseArgs(&s, fs, test.args); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if s.Body != test.body || string(s.Payload) != test.payload {
+			t.Errorf("Unexpected values for %v: %q, %q", test.args, s.Body, s.Payload)
+		}
+	}
+}
+
+func TestInputFlagStdinOnce(t *testing.T) {
+	replaceStdin(t, "from stdin")
+	var s inputConfig
+	fs := flag.NewFlagSet("inputonce", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-body", "-", "-payload", "-"})
+	if err == nil || !strings.Contains(err.Error(), "standard input is already read by flag -body") {
+		t.Fatal("Expected an error for reading standard input twice, but got", err)
+	}
+}
+
+func TestInputFlagMissingFile(t *testing.T) {
+	var s inputConfig
+	fs := flag.NewFlagSet("inputmissing", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if ConfigureFlagsetAndParseArgs(&s, fs, []string{"-body", "@" + filepath.Join(t.TempDir(), "missing")}) == nil {
+		t.Fatal("Expected an error for a missing file.")
+	}
+}
+
+func TestInputFlagUnsupportedType(t *testing.T) {
+	var s = struct {
+		Count int `flag:"count,,Count." flagopt:"input"`
+	}{}
+	if ConfigureFlagset(&s, flag.NewFlagSet("inputtype", flag.ContinueOnError)) == nil {
+		t.Fatal("Expected an error for an input flag of type int.")
+	}
+}
+
+func TestInputFlagFormatArgs(t *testing.T) {
+	var s inputConfig
+	fs := flag.NewFlagSet("inputformat", flag.ContinueOnError)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-body", "@@x", "-payload", "y"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var copied inputConfig
+	fs2 := flag.NewFlagSet("inputformat2", flag.ContinueOnError)
+	if err := ConfigureFlagsetAndParseArgs(&copied, fs2, FormatArgs(fs, true)); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if copied.Body != "@x" || string(copied.Payload) != "y" {
+		t.Fatalf("Unexpected values after round trip: %q, %q", copied.Body, copied.Payload)
+	}
+}
+
+func TestInputFlagUsage(t *testing.T) {
+	var s inputConfig
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("inputusage", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	writeDefaults(&output, fs, 80)
+	expected := "  -body string\n        Body of the request. (default \"@@default\")\n  -payload string\n        Payload of the request.\n"
+	if output.String() != expected {
+		t.Fatal("Unexpected usage:", output.String())
+	}
+}
+
+func TestInputFlagStdinOnceOptional(t *testing.T) {
+	replaceStdin(t, "from stdin")
+	var s = struct {
+		A *string `flag:"a,,A." flagopt:"input"`
+		B *string `flag:"b,,B." flagopt:"input"`
+	}{}
+	fs := flag.NewFlagSet("inputonceoptional", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var count = len(allStates())
+	err := parse(fs, []string{"-a", "-", "-b", "-"})
+	if len(allStates()) != count {
+		t.Error("Expected no additional flag set states while parsing.")
+	}
+	if err == nil || !strings.Contains(err.Error(), "standard input is already read by flag -a") {
+		t.Fatal("Expected an error for reading standard input twice, but got", err)
+	}
+	if s.A == nil || *s.A != "from stdin" {
+		t.Fatal("Expected first field to be read from standard input.")
+	}
+	if s.B != nil {
+		t.Fatal("Expected second field to stay nil.")
+	}
+}
+
+func TestInputFlagResponseFiles(t *testing.T) {
+	var dir = t.TempDir()
+	var payload = filepath.Join(dir, "payload.json")
+	if err := os.WriteFile(payload, []byte(`{"a": 1}`), 0600); err != nil {
+		t.Fatal("Failed to write file:", err)
+	}
+	var args = writeResponseFile(t, dir, "args.txt", "-payload @"+payload+"\n")
+	var testset = []struct {
+		name string
+		args []string
+	}{
+		{"command line", []string{"-body", "@" + payload}},
+		{"abbreviated", []string{"-bo", "@" + payload}},
+		{"response file", []string{"@" + args}},
+	}
+	for _, test := range testset {
+		var s inputConfig
+		fs := flag.NewFlagSet("inputresponse", flag.ContinueOnError)
+		EnableResponseFiles(fs)
+		EnableAbbreviations(fs)
+		if err := ConfigureFlagsetAndParseArgs(&s, fs, test.args); err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if s.Body != `{"a": 1}` && string(s.Payload) != `{"a": 1}` {
+			t.Errorf("Expected content of the file for %s, but got %q, %q", test.name, s.Body, s.Payload)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package flagtag
+
+import (
+	"flag"
+	"reflect"
+)
+
+// IsSet checks whether the value of a field of the config value was set
+// explicitly, e.g. on the command line, as opposed to having its default
+// value. The field is identified by its address:
+//
+//	flagtag.IsSet(&config, &config.Timeout)
+//
+// The config value must have been configured by flagtag before. IsSet returns
+// false if the field is not part of the config value or if no flag was
+// configured for the field. For pointer fields, this makes it possible to
+// distinguish an explicitly set value from the default value.
+func IsSet(config interface{}, field interface{}) bool {
+	structValue, err := getStructValue(config)
+	if err != nil {
+		return false
+	}
+	var fieldPtr = reflect.ValueOf(field)
+	if !fieldPtr.IsValid() || fieldPtr.Kind() != reflect.Ptr || fieldPtr.IsNil() {
+		return false
+	}
+	var start = structValue.Addr().Pointer()
+	var address = fieldPtr.Pointer()
+	if address < start || address >= start+structValue.Type().Size() {
+		return false
+	}
+	var fieldType = fieldPtr.Type().Elem()
+	for flagset, state := range allStates() {
+		for _, record := range state.records() {
+			if record.declared.Addr().Pointer() == address && record.typ == fieldType && sourceOf(flagset, record).Kind != SourceDefault {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allStates returns the flagtag state of all flag sets.
+func allStates() map[*flag.FlagSet]*flagsetState {
+	states.Lock()
+	defer states.Unlock()
+	var all = make(map[*flag.FlagSet]*flagsetState, len(states.m))
+	for flagset, state := range states.m {
+		all[flagset] = state
+	}
+	return all
+}
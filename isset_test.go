@@ -0,0 +1,42 @@
+package flagtag
+
+import (
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestIsSet(t *testing.T) {
+	var s = struct {
+		Verbose bool `flag:"verbose,false,Verbose output."`
+		Count   int  `flag:"count,1,The count."`
+		Server  struct {
+			Timeout *time.Duration `flag:"timeout,5s,The timeout."`
+		}
+		Untagged int
+	}{}
+	s.Server.Timeout = new(time.Duration)
+	fs := flag.NewFlagSet("isset", flag.ContinueOnError)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-count", "1", "-timeout", "1s"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if IsSet(&s, &s.Verbose) {
+		t.Error("Expected verbose not to be set.")
+	}
+	if !IsSet(&s, &s.Count) {
+		t.Error("Expected count to be set, even though it has its default value.")
+	}
+	if !IsSet(&s, &s.Server.Timeout) {
+		t.Error("Expected timeout to be set.")
+	}
+	if IsSet(&s, &s.Server) {
+		t.Error("Expected struct field to not be considered set.")
+	}
+	if IsSet(&s, &s.Untagged) {
+		t.Error("Expected untagged field to not be set.")
+	}
+	var other int
+	if IsSet(&s, &other) || IsSet(&s, nil) || IsSet(nil, &s.Count) || IsSet(&s, s.Count) {
+		t.Error("Expected invalid arguments to not be set.")
+	}
+}
@@ -0,0 +1,160 @@
+package flagtag
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"math"
+	"strconv"
+)
+
+// jsonSchemaDialect is the JSON Schema dialect of the schemas written by
+// WriteJSONSchema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// WriteJSONSchema writes a JSON Schema document to w that describes the
+// configuration of all flags configured by flagtag in the flag set. Every flag
+// is described by a property with the flag's name, including its type,
+// default value and description. Flags declared in nested structs are
+// described by properties of nested objects, named after the struct fields.
+func WriteJSONSchema(w io.Writer, flagset *flag.FlagSet) error {
+	var properties = nestRecords(flagset, lookupState(flagset).records(), func(record *flagRecord, f *flag.Flag) interface{} {
+		var schema = jsonObject{{"type", jsonType(f)}}
+		if !isOptionalFlag(f.Value) && secretOf(f.Value) == nil {
+			schema = append(schema, jsonMember{"default", jsonValue(f, f.DefValue)})
+		}
+		if valueTypeName(f.Value) == "uint" || valueTypeName(f.Value) == "uint64" {
+			schema = append(schema, jsonMember{"minimum", 0})
+		}
+		if _, usage := unquoteUsage(flagset, f); usage != "" {
+			schema = append(schema, jsonMember{"description", usage})
+		}
+		if record.tag.Options.Deprecated != "" {
+			schema = append(schema, jsonMember{"deprecated", true})
+		}
+		return schema
+	}, func(properties jsonObject) interface{} {
+		return jsonObject{{"type", "object"}, {"properties", properties}}
+	})
+	var document = jsonObject{
+		{"$schema", jsonSchemaDialect},
+		{"type", "object"},
+		{"properties", properties},
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// nestRecords builds a JSON object from the provided records of flags
+// configured by flagtag in the flag set, in order. The value of every flag is
+// produced by leaf. Flags declared in nested structs are collected in nested
+// objects, named after the struct field, which are converted by node.
+func nestRecords(flagset *flag.FlagSet, records []*flagRecord, leaf func(*flagRecord, *flag.Flag) interface{}, node func(jsonObject) interface{}) jsonObject {
+	type object struct {
+		members jsonObject
+		// children contains the nested objects by the index of the
+		// member that they are converted into.
+		children map[int]*object
+		indices  map[string]int
+	}
+	var newObject = func() *object {
+		return &object{children: make(map[int]*object), indices: make(map[string]int)}
+	}
+	var root = newObject()
+	for _, record := range records {
+		var f = flagset.Lookup(record.name)
+		if f == nil {
+			continue
+		}
+		var current = root
+		for _, parent := range record.parents {
+			index, ok := current.indices[parent]
+			if !ok {
+				index = len(current.members)
+				current.indices[parent] = index
+				current.children[index] = newObject()
+				current.members = append(current.members, jsonMember{key: parent})
+			}
+			current = current.children[index]
+		}
+		current.members = append(current.members, jsonMember{record.name, leaf(record, f)})
+	}
+	var convert func(o *object) jsonObject
+	convert = func(o *object) jsonObject {
+		for index, child := range o.children {
+			o.members[index].value = node(convert(child))
+		}
+		return o.members
+	}
+	return convert(root)
+}
+
+// jsonType returns the JSON type of the flag's value.
+func jsonType(f *flag.Flag) string {
+	switch valueTypeName(f.Value) {
+	case "":
+		if isBoolFlag(unwrapValue(f.Value)) {
+			return "boolean"
+		}
+	case "int", "int64", "uint", "uint64":
+		return "integer"
+	case "float":
+		return "number"
+	}
+	return "string"
+}
+
+// jsonValue converts the string representation of a flag's value into a value
+// of the corresponding JSON type. Values that have no JSON representation of
+// that type, such as NaN and infinite numbers, are kept as strings.
+func jsonValue(f *flag.Flag, value string) interface{} {
+	switch jsonType(f) {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+			return json.Number(value)
+		}
+	}
+	return value
+}
+
+// jsonObject is a JSON object that preserves the order of its members.
+type jsonObject []jsonMember
+
+// jsonMember is a single member of a JSON object.
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+// MarshalJSON encodes the object with its members in order.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(member.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
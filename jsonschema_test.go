@@ -0,0 +1,110 @@
+package flagtag
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJSONSchema(t *testing.T) {
+	var s = struct {
+		Verbose bool `flag:"verbose,false,Enable verbose output."`
+		Server  struct {
+			Port    uint          `flag:"port,8080,The port to listen on."`
+			Timeout time.Duration `flag:"timeout,5s,The timeout." flagopt:"deprecated"`
+		}
+		Embedded
+		Ratio float64  `flag:"ratio,0.5,"`
+		Value dummyInt `flag:"value,,Custom value."`
+	}{}
+	fs := flag.NewFlagSet("jsonschema", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var b strings.Builder
+	if err := WriteJSONSchema(&b, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "verbose": {
+      "type": "boolean",
+      "default": false,
+      "description": "Enable verbose output."
+    },
+    "Server": {
+      "type": "object",
+      "properties": {
+        "port": {
+          "type": "integer",
+          "default": 8080,
+          "minimum": 0,
+          "description": "The port to listen on."
+        },
+        "timeout": {
+          "type": "string",
+          "default": "5s",
+          "description": "The timeout.",
+          "deprecated": true
+        }
+      }
+    },
+    "name": {
+      "type": "string",
+      "default": "embedded",
+      "description": "Name from embedded struct."
+    },
+    "ratio": {
+      "type": "number",
+      "default": 0.5
+    },
+    "value": {
+      "type": "string",
+      "default": "0",
+      "description": "Custom value."
+    }
+  }
+}
+`
+	if b.String() != expected {
+		t.Fatal("Unexpected JSON schema:\n" + b.String())
+	}
+	if !json.Valid([]byte(b.String())) {
+		t.Fatal("Expected valid JSON.")
+	}
+}
+
+func TestWriteJSONNonFinite(t *testing.T) {
+	var s = struct {
+		Ratio float64 `flag:"ratio,NaN,Ratio."`
+		Limit float64 `flag:"limit,1,Limit."`
+	}{}
+	fs := flag.NewFlagSet("jsonnonfinite", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	s.Limit = math.Inf(-1)
+	var b strings.Builder
+	if err := WriteJSONSchema(&b, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !strings.Contains(b.String(), `"default": "NaN"`) {
+		t.Fatal("Expected NaN default value as string, but got", b.String())
+	}
+	b.Reset()
+	if err := WriteJSONConfig(&b, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if expected := "{\n  \"ratio\": \"NaN\",\n  \"limit\": \"-Inf\"\n}\n"; b.String() != expected {
+		t.Fatal("Unexpected JSON configuration:", b.String())
+	}
+}
+
+type Embedded struct {
+	Name string `flag:"name,embedded,Name from embedded struct."`
+}
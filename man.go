@@ -0,0 +1,155 @@
+package flagtag
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+)
+
+// ManPage contains the information of a man page that cannot be derived from
+// the flags.
+type ManPage struct {
+	// Name is the name of the program.
+	Name string
+	// Section is the section of the manual. It defaults to "1".
+	Section string
+	// Summary is the one-line description of the program shown in the NAME
+	// section.
+	Summary string
+	// Synopsis is the usage pattern shown in the SYNOPSIS section, following
+	// the program name. It defaults to "[options]".
+	Synopsis string
+	// Description is the text of the DESCRIPTION section. Paragraphs are
+	// separated by empty lines. The section is omitted if Description is
+	// empty.
+	Description string
+	// Date, Source and Manual are shown in the header and footer of the page.
+	Date   string
+	Source string
+	Manual string
+	// Environment lists the environment variables that affect the program.
+	Environment []ManEntry
+	// Files lists the files that the program uses.
+	Files []ManEntry
+}
+
+// ManEntry is an entry of the ENVIRONMENT or FILES section of a man page.
+type ManEntry struct {
+	Name        string
+	Description string
+}
+
+// WriteManPage writes a man page in roff format for the program to w. The
+// OPTIONS section lists all flags of the flag set in the same order and
+// groups as they are shown in usage information, including their default
+// values. The ENVIRONMENT section lists the entries of the page, followed by
+// the environment variables of flags with the 'file' flag option.
+func WriteManPage(w io.Writer, flagset *flag.FlagSet, page ManPage) error {
+	if page.Name == "" {
+		return errors.New("man page requires the name of the program")
+	}
+	var section = page.Section
+	if section == "" {
+		section = "1"
+	}
+	var synopsis = page.Synopsis
+	if synopsis == "" {
+		synopsis = "[options]"
+	}
+	var b strings.Builder
+	b.WriteString(".TH " + roffQuote(strings.ToUpper(page.Name)) + " " + roffQuote(section) + " " + roffQuote(page.Date) + " " + roffQuote(page.Source) + " " + roffQuote(page.Manual) + "\n")
+	b.WriteString(".SH NAME\n")
+	if page.Summary == "" {
+		b.WriteString(roffText(page.Name) + "\n")
+	} else {
+		b.WriteString(roffText(page.Name) + " \\- " + roffText(page.Summary) + "\n")
+	}
+	b.WriteString(".SH SYNOPSIS\n")
+	b.WriteString(".B " + roffText(page.Name) + "\n")
+	b.WriteString(roffText(synopsis) + "\n")
+	if page.Description != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		for i, paragraph := range strings.Split(strings.TrimSpace(page.Description), "\n\n") {
+			if i > 0 {
+				b.WriteString(".PP\n")
+			}
+			b.WriteString(roffText(strings.Join(strings.Fields(paragraph), " ")) + "\n")
+		}
+	}
+	var sections = usageSections(flagset)
+	if len(sections) > 1 || len(sections[0].flags) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		for _, s := range sections {
+			if s.group != "" {
+				b.WriteString(".SS " + roffQuote(s.group) + "\n")
+			}
+			for _, f := range s.flags {
+				writeManOption(&b, flagset, f)
+			}
+		}
+	}
+	var environment = page.Environment
+	for _, record := range lookupState(flagset).records() {
+		if record.tag.Options.File && !record.tag.Options.Hidden {
+			environment = append(environment, ManEntry{Name: fileEnvName(record.name), Description: "The file to read the value of -" + record.name + " from, unless the flag is specified."})
+		}
+	}
+	writeManEntries(&b, "ENVIRONMENT", "\\fB", environment)
+	writeManEntries(&b, "FILES", "\\fI", page.Files)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeManOption writes the entry of a single flag in the OPTIONS section.
+func writeManOption(b *strings.Builder, flagset *flag.FlagSet, f *flag.Flag) {
+	name, usage := unquoteUsage(flagset, f)
+	b.WriteString(".TP\n")
+	b.WriteString("\\fB\\-" + roffOption(f.Name) + "\\fR")
+	if name != "" {
+		b.WriteString(" \\fI" + roffText(name) + "\\fR")
+	}
+	b.WriteString("\n")
+	if def, ok := defaultText(f); ok {
+		usage += " (default " + def + ")"
+	}
+	if deprecated := deprecationText(flagset, f); deprecated != "" {
+		usage += " (deprecated: " + deprecated + ")"
+	}
+	b.WriteString(roffText(usage) + "\n")
+}
+
+// writeManEntries writes a section consisting of a list of entries. The font
+// is the font escape sequence with which entry names are shown. Nothing is
+// written if there are no entries.
+func writeManEntries(b *strings.Builder, title string, font string, entries []ManEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	b.WriteString(".SH " + title + "\n")
+	for _, entry := range entries {
+		b.WriteString(".TP\n")
+		b.WriteString(font + roffText(entry.Name) + "\\fR\n")
+		b.WriteString(roffText(entry.Description) + "\n")
+	}
+}
+
+// roffText escapes text for use in a roff document.
+func roffText(text string) string {
+	text = strings.Replace(text, `\`, `\e`, -1)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+// roffOption escapes an option name for use in a roff document. In contrast to
+// roffText, hyphens are escaped such that they are rendered as minus signs.
+func roffOption(name string) string {
+	return strings.Replace(roffText(name), "-", `\-`, -1)
+}
+
+// roffQuote quotes an argument of a roff request.
+func roffQuote(text string) string {
+	return `"` + strings.Replace(roffText(text), `"`, `""`, -1) + `"`
+}
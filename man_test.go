@@ -0,0 +1,92 @@
+package flagtag
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestWriteManPage(t *testing.T) {
+	var s = struct {
+		Verbose bool   `flag:"verbose,false,Enable verbose output."`
+		Listen  string `flag:"listen,:8080,Listen on {ADDR}."`
+		Server  struct {
+			Root string `flag:"root-dir,/srv,Serve files from this directory."`
+		}
+		Old string `flag:"old,,Old flag." flagopt:"hidden"`
+	}{}
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var b strings.Builder
+	err := WriteManPage(&b, fs, ManPage{
+		Name:        "server",
+		Summary:     "serve files",
+		Description: "Serves files\nover HTTP.\n\n.Second paragraph with a \\ backslash.",
+		Date:        "2014-07-14",
+		Source:      "server 1.0",
+		Manual:      "User Commands",
+		Environment: []ManEntry{{"TMPDIR", "Directory for temporary files."}},
+		Files:       []ManEntry{{"/etc/server.conf", "Configuration file."}},
+	})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := `.TH "SERVER" "1" "2014-07-14" "server 1.0" "User Commands"
+.SH NAME
+server \- serve files
+.SH SYNOPSIS
+.B server
+[options]
+.SH DESCRIPTION
+Serves files over HTTP.
+.PP
+\&.Second paragraph with a \e backslash.
+.SH OPTIONS
+.TP
+\fB\-verbose\fR
+Enable verbose output.
+.TP
+\fB\-listen\fR \fIADDR\fR
+Listen on ADDR. (default ":8080")
+.SS "Server"
+.TP
+\fB\-root\-dir\fR \fIstring\fR
+Serve files from this directory. (default "/srv")
+.SH ENVIRONMENT
+.TP
+\fBTMPDIR\fR
+Directory for temporary files.
+.SH FILES
+.TP
+\fI/etc/server.conf\fR
+Configuration file.
+`
+	if b.String() != expected {
+		t.Fatal("Unexpected man page:\n" + b.String())
+	}
+}
+
+func TestWriteManPageWithoutName(t *testing.T) {
+	var b strings.Builder
+	if err := WriteManPage(&b, flag.NewFlagSet("", flag.ContinueOnError), ManPage{}); err == nil {
+		t.Fatal("Expected an error because the program name is missing.")
+	}
+}
+
+func TestWriteManPageFileEnvironment(t *testing.T) {
+	var s fileConfig
+	fs := flag.NewFlagSet("manfile", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var b strings.Builder
+	if err := WriteManPage(&b, fs, ManPage{Name: "tool", Environment: []ManEntry{{"TOOL_HOME", "Home directory."}}}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := ".SH ENVIRONMENT\n.TP\n\\fBTOOL_HOME\\fR\nHome directory.\n.TP\n\\fBDB_PASSWORD_FILE\\fR\nThe file to read the value of -db-password from, unless the flag is specified.\n"
+	if !strings.HasSuffix(b.String(), expected) {
+		t.Fatal("Unexpected man page:", b.String())
+	}
+}
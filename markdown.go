@@ -0,0 +1,67 @@
+package flagtag
+
+import (
+	"flag"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes a Markdown table describing all flags of the flag set
+// to w. Flags are listed in the same order as they are shown in usage
+// information, with a column for each of the flag's name, former names (see
+// the 'was' flag option), type, default value, description and group. Hidden
+// flags are omitted. The output only depends on the configuration of the flag
+// set, such that it can be generated and compared automatically.
+func WriteMarkdown(w io.Writer, flagset *flag.FlagSet) error {
+	var state = lookupState(flagset)
+	var b strings.Builder
+	b.WriteString("| Flag | Aliases | Type | Default | Description | Group |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, section := range usageSections(flagset) {
+		for _, f := range section.flags {
+			var aliases []string
+			if record := state.record(f.Name); record != nil {
+				for _, alias := range record.tag.Options.Was {
+					aliases = append(aliases, markdownCode("-"+alias))
+				}
+			}
+			placeholder, usage := unquoteUsage(flagset, f)
+			var typeName = valueTypeName(f.Value)
+			if typeName == "" {
+				typeName = "bool"
+			}
+			if placeholder != "" && placeholder != typeName {
+				typeName += " (" + placeholder + ")"
+			}
+			var def string
+			if f.DefValue != "" {
+				def = markdownCode(f.DefValue)
+			}
+			if deprecated := deprecationText(flagset, f); deprecated != "" {
+				usage += " (deprecated: " + deprecated + ")"
+			}
+			var cells = []string{markdownCode("-" + f.Name), strings.Join(aliases, ", "), markdownText(typeName), def, markdownText(usage), markdownText(section.group)}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownText escapes text for use in a Markdown table cell.
+func markdownText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ", "`", "\\`", "*", `\*`, "_", `\_`, "<", "&lt;").Replace(text)
+}
+
+// markdownCode formats text as inline code for use in a Markdown table cell.
+func markdownCode(text string) string {
+	var fence = "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	var padding string
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") || strings.TrimSpace(text) == "" {
+		padding = " "
+	}
+	return fence + padding + strings.Replace(text, "|", `\|`, -1) + padding + fence
+}
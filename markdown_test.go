@@ -0,0 +1,54 @@
+package flagtag
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	var s = struct {
+		Verbose bool          `flag:"verbose,false,Enable verbose output."`
+		Listen  string        `flag:"listen,:8080,Listen on {ADDR}."`
+		Timeout time.Duration `flag:"timeout,5s,Timeout | limit for *all* requests." flagopt:"was=deadline"`
+		Server  struct {
+			Root string `flag:"root-dir,,Serve files from this directory."`
+		}
+		Secret bool `flag:"secret,false,Hidden." flagopt:"hidden"`
+	}{}
+	fs := flag.NewFlagSet("markdown", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var b strings.Builder
+	if err := WriteMarkdown(&b, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := "| Flag | Aliases | Type | Default | Description | Group |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `-verbose` |  | bool | `false` | Enable verbose output. |  |\n" +
+		"| `-listen` |  | string (ADDR) | `:8080` | Listen on ADDR. |  |\n" +
+		"| `-timeout` | `-deadline` | duration | `5s` | Timeout \\| limit for \\*all\\* requests. |  |\n" +
+		"| `-root-dir` |  | string |  | Serve files from this directory. | Server |\n"
+	if b.String() != expected {
+		t.Fatal("Unexpected Markdown output:\n" + b.String())
+	}
+}
+
+func TestMarkdownCode(t *testing.T) {
+	var testset = []struct {
+		text     string
+		expected string
+	}{
+		{"abc", "`abc`"},
+		{"a|b", "`a\\|b`"},
+		{"a`b", "``a`b``"},
+		{"`a", "`` `a ``"},
+	}
+	for nr, test := range testset {
+		if result := markdownCode(test.text); result != test.expected {
+			t.Errorf("Test entry %d failed: %s", nr, result)
+		}
+	}
+}
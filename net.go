@@ -0,0 +1,199 @@
+package flagtag
+
+import (
+	"errors"
+	"flag"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// textType describes how values of a type that is not supported by the flag
+// package are parsed from and formatted into text.
+type textType struct {
+	// name is the name of the type as shown in usage information.
+	name   string
+	parse  func(text string) (interface{}, error)
+	format func(value interface{}) string
+}
+
+// textTypes contains the supported types that are not supported by the flag
+// package, by their exact type. time.Time is not included, since its format
+// depends on the 'layout' flag option.
+var textTypes = map[reflect.Type]textType{
+	reflect.TypeOf(net.IP{}): {
+		name: "ip",
+		parse: func(text string) (interface{}, error) {
+			if ip := net.ParseIP(text); ip != nil {
+				return ip, nil
+			}
+			return nil, errors.New("invalid IP address")
+		},
+		format: func(value interface{}) string {
+			if ip := value.(net.IP); ip != nil {
+				return ip.String()
+			}
+			return ""
+		},
+	},
+	reflect.TypeOf(net.IPNet{}): {
+		name: "cidr",
+		parse: func(text string) (interface{}, error) {
+			_, ipnet, err := net.ParseCIDR(text)
+			if err != nil {
+				return nil, err
+			}
+			return *ipnet, nil
+		},
+		format: func(value interface{}) string {
+			if ipnet := value.(net.IPNet); ipnet.IP != nil {
+				return ipnet.String()
+			}
+			return ""
+		},
+	},
+	reflect.TypeOf(netip.Addr{}): {
+		name: "ip",
+		parse: func(text string) (interface{}, error) {
+			return netip.ParseAddr(text)
+		},
+		format: func(value interface{}) string {
+			if addr := value.(netip.Addr); addr.IsValid() {
+				return addr.String()
+			}
+			return ""
+		},
+	},
+	reflect.TypeOf(netip.Prefix{}): {
+		name: "cidr",
+		parse: func(text string) (interface{}, error) {
+			return netip.ParsePrefix(text)
+		},
+		format: func(value interface{}) string {
+			if prefix := value.(netip.Prefix); prefix.IsValid() {
+				return prefix.String()
+			}
+			return ""
+		},
+	},
+	reflect.TypeOf(netip.AddrPort{}): {
+		name: "ip:port",
+		parse: func(text string) (interface{}, error) {
+			return netip.ParseAddrPort(text)
+		},
+		format: func(value interface{}) string {
+			if addrPort := value.(netip.AddrPort); addrPort.IsValid() {
+				return addrPort.String()
+			}
+			return ""
+		},
+	},
+	locationType: {
+		name: "timezone",
+		parse: func(text string) (interface{}, error) {
+			return time.LoadLocation(text)
+		},
+		format: func(value interface{}) string {
+			if location := value.(*time.Location); location != nil {
+				return location.String()
+			}
+			return ""
+		},
+	},
+	reflect.TypeOf(url.URL{}): {
+		name: "url",
+		parse: func(text string) (interface{}, error) {
+			u, err := url.Parse(text)
+			if err != nil {
+				return nil, err
+			}
+			if !u.IsAbs() {
+				return nil, errors.New("URL '" + text + "' is not absolute")
+			}
+			return *u, nil
+		},
+		format: func(value interface{}) string {
+			var u = value.(url.URL)
+			return u.String()
+		},
+	},
+}
+
+// hostPortType describes string values in 'host:port' form, as required by
+// the 'hostport' flag option.
+var hostPortType = textType{
+	name: "host:port",
+	parse: func(text string) (interface{}, error) {
+		_, port, err := net.SplitHostPort(text)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return nil, errors.New("invalid port '" + port + "'")
+		}
+		return text, nil
+	},
+	format: func(value interface{}) string {
+		return value.(string)
+	},
+}
+
+// registerTextFlag registers a flag for a field of one of the types in
+// textTypes, a time.Time field, or a string field with the 'hostport' flag
+// option. ok is false if the field is not of such a type.
+func registerTextFlag(fieldName string, fieldValue reflect.Value, tag *flagTag, flagset *flag.FlagSet) (ok bool, err error) {
+	typ, ok := textTypes[fieldValue.Type()]
+	if fieldValue.Type() == timeType {
+		typ, ok = timeTextType(tag.Options.Layout), true
+	}
+	if tag.Options.HostPort {
+		if fieldValue.Kind() != reflect.String {
+			return true, errors.New("field '" + fieldName + "' (tag '" + tag.Name + "'): flag option 'hostport' requires a string field")
+		}
+		typ, ok = hostPortType, true
+	}
+	if !ok {
+		return false, nil
+	}
+	var value = &textValue{field: fieldValue, typ: typ}
+	if tag.DefaultValue != "" {
+		if err := value.Set(tag.DefaultValue); err != nil {
+			return true, &ErrInvalidDefault{fieldName, tag.Name, err}
+		}
+	}
+	flagset.Var(value, tag.Name, tag.Description)
+	return true, nil
+}
+
+// textValue is the flag value of a field of a type described by textType.
+type textValue struct {
+	field reflect.Value
+	typ   textType
+}
+
+func (v *textValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	return v.typ.format(v.field.Interface())
+}
+
+func (v *textValue) Set(text string) error {
+	value, err := v.typ.parse(text)
+	if err != nil {
+		return err
+	}
+	v.field.Set(reflect.ValueOf(value).Convert(v.field.Type()))
+	return nil
+}
+
+func (v *textValue) typeName() string {
+	return v.typ.name
+}
+
+func (v *textValue) Get() interface{} {
+	return v.field.Interface()
+}
@@ -0,0 +1,116 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+type netConfig struct {
+	IP       net.IP         `flag:"ip,127.0.0.1,IP address."`
+	Network  net.IPNet      `flag:"network,,Network."`
+	Addr     netip.Addr     `flag:"addr,,Address."`
+	Prefix   netip.Prefix   `flag:"prefix,,Prefix."`
+	AddrPort netip.AddrPort `flag:"addr-port,,Address and port."`
+	Endpoint *url.URL       `flag:"endpoint,,Endpoint."`
+	Proxy    *url.URL       `flag:"proxy,http://proxy:3128,Proxy."`
+	Listen   string         `flag:"listen,:8080,Listen address." flagopt:"hostport"`
+}
+
+func TestNetFlags(t *testing.T) {
+	var s netConfig
+	fs := flag.NewFlagSet("net", flag.ContinueOnError)
+	args := []string{"-network", "10.1.2.3/8", "-addr", "::1", "-prefix", "192.168.0.0/16", "-addr-port", "[::1]:53",
+		"-endpoint", "https://example.com/api", "-listen", "localhost:443"}
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, args); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !s.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Error("Unexpected IP:", s.IP)
+	}
+	if s.Network.String() != "10.0.0.0/8" {
+		t.Error("Unexpected network:", s.Network.String())
+	}
+	if s.Addr != netip.IPv6Loopback() {
+		t.Error("Unexpected address:", s.Addr)
+	}
+	if s.Prefix != netip.MustParsePrefix("192.168.0.0/16") {
+		t.Error("Unexpected prefix:", s.Prefix)
+	}
+	if s.AddrPort != netip.MustParseAddrPort("[::1]:53") {
+		t.Error("Unexpected address and port:", s.AddrPort)
+	}
+	if s.Endpoint == nil || s.Endpoint.Host != "example.com" || s.Endpoint.Path != "/api" {
+		t.Error("Unexpected endpoint:", s.Endpoint)
+	}
+	if s.Proxy == nil || s.Proxy.String() != "http://proxy:3128" {
+		t.Error("Unexpected proxy:", s.Proxy)
+	}
+	if s.Listen != "localhost:443" {
+		t.Error("Unexpected listen address:", s.Listen)
+	}
+	if v := fs.Lookup("network").Value.String(); v != "10.0.0.0/8" {
+		t.Error("Unexpected string representation:", v)
+	}
+}
+
+func TestNetFlagsInvalidValue(t *testing.T) {
+	var testset = [][]string{
+		{"-ip", "1.2.3"},
+		{"-network", "10.0.0.0"},
+		{"-addr", "localhost"},
+		{"-prefix", "::1"},
+		{"-addr-port", "127.0.0.1"},
+		{"-endpoint", "/relative"},
+		{"-listen", "localhost"},
+		{"-listen", "localhost:99999"},
+	}
+	for _, args := range testset {
+		var s netConfig
+		fs := flag.NewFlagSet("netinvalid", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if ConfigureFlagsetAndParseArgs(&s, fs, args) == nil {
+			t.Error("Expected an error for", args)
+		}
+	}
+}
+
+func TestNetFlagsInvalidDefault(t *testing.T) {
+	var s = struct {
+		IP net.IP `flag:"ip,localhost,IP address."`
+	}{}
+	err := ConfigureFlagset(&s, flag.NewFlagSet("netdefault", flag.ContinueOnError))
+	if _, ok := err.(*ErrInvalidDefault); !ok {
+		t.Fatal("Expected an invalid default error, but got", err)
+	}
+}
+
+func TestHostPortRequiresString(t *testing.T) {
+	var s = struct {
+		Port int `flag:"port,,Port." flagopt:"hostport"`
+	}{}
+	if ConfigureFlagset(&s, flag.NewFlagSet("hostport", flag.ContinueOnError)) == nil {
+		t.Fatal("Expected an error for flag option 'hostport' on an int field.")
+	}
+}
+
+func TestNetFlagsUsage(t *testing.T) {
+	var s = struct {
+		IP     net.IP `flag:"ip,127.0.0.1,IP address."`
+		Listen string `flag:"listen,,Listen address." flagopt:"hostport"`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("netusage", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	writeDefaults(&output, fs, 80)
+	expected := "  -ip ip\n        IP address. (default 127.0.0.1)\n  -listen host:port\n        Listen address.\n"
+	if output.String() != expected {
+		t.Fatal("Unexpected usage:", output.String())
+	}
+}
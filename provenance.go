@@ -0,0 +1,63 @@
+package flagtag
+
+import (
+	"flag"
+	"io"
+	"text/tabwriter"
+)
+
+// recordSources records the sources of the values of the flags that are set
+// by the parsed arguments. The origins correspond to the arguments and
+// identify the response files that arguments were read from. origins may be
+// nil if no response files were expanded.
+func recordSources(flagset *flag.FlagSet, args []string, origins []argOrigin) {
+	var state = lookupState(flagset)
+	visitFlagArgs(flagset, args, func(index int, name string) (string, error) {
+		var record = state.record(name)
+		if record == nil {
+			record = state.alias(name)
+		}
+		if record == nil {
+			if record = state.fileRecord(name); record != nil {
+				state.setSource(record, Source{Kind: SourceFile, Name: flagset.Lookup(name).Value.String()})
+			}
+			return name, nil
+		}
+		var source = Source{Kind: SourceCommandLine}
+		if origins != nil && origins[index].file != "" {
+			source = Source{Kind: SourceResponseFile, Name: origins[index].file, Line: origins[index].line}
+		}
+		state.setSource(record, source)
+		return name, nil
+	})
+}
+
+// SourceOf returns the source of the current value of the named flag. ok is
+// false if the flag was not configured by flagtag. Sources other than the
+// default value and the command line are only known if the arguments were
+// parsed by one of the parse functions of this package.
+func SourceOf(flagset *flag.FlagSet, name string) (source Source, ok bool) {
+	var record = lookupState(flagset).record(name)
+	if record == nil {
+		return Source{}, false
+	}
+	return sourceOf(flagset, record), true
+}
+
+// WriteSources writes the current values of all flags configured by flagtag
+// to w, in order of declaration, together with the source of each value. The
+// output is meant for humans, e.g. to support debugging of a program's
+// configuration.
+func WriteSources(w io.Writer, flagset *flag.FlagSet) error {
+	var tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, record := range lookupState(flagset).records() {
+		var f = flagset.Lookup(record.name)
+		if f == nil {
+			continue
+		}
+		if _, err := io.WriteString(tw, "-"+f.Name+"="+f.Value.String()+"\t"+sourceOf(flagset, record).String()+"\n"); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,84 @@
+package flagtag
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceOf(t *testing.T) {
+	dir := t.TempDir()
+	path := writeResponseFile(t, dir, "args.txt", "-name from-file\n\n-count 2\n")
+	var s = struct {
+		Verbose bool   `flag:"verbose,false,Verbose output."`
+		Name    string `flag:"name,,The name."`
+		Count   int    `flag:"count,1,The count." flagopt:"was=times"`
+		Other   string `flag:"other,default,Other value."`
+	}{}
+	fs := flag.NewFlagSet("sources", flag.ContinueOnError)
+	SetWarningOutput(fs, io.Discard)
+	EnableResponseFiles(fs)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-verbose", "@" + path, "-times", "3"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var testset = []struct {
+		name   string
+		source Source
+	}{
+		{"verbose", Source{Kind: SourceCommandLine}},
+		{"name", Source{Kind: SourceResponseFile, Name: path, Line: 1}},
+		{"count", Source{Kind: SourceCommandLine}},
+		{"other", Source{Kind: SourceDefault}},
+	}
+	for nr, test := range testset {
+		if source, ok := SourceOf(fs, test.name); !ok || source != test.source {
+			t.Error("Test entry", nr, "failed with source", source)
+		}
+	}
+	if _, ok := SourceOf(fs, "times"); ok {
+		t.Error("Expected no source for former flag names.")
+	}
+	if source, _ := SourceOf(fs, "name"); source.String() != "response file "+filepath.Join(dir, "args.txt")+":1" {
+		t.Error("Unexpected source description:", source.String())
+	}
+}
+
+func TestSourceOfDirectlyParsed(t *testing.T) {
+	var s = struct {
+		Name string `flag:"name,,The name."`
+	}{}
+	fs := flag.NewFlagSet("sourcesdirect", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if source, _ := SourceOf(fs, "name"); source.Kind != SourceDefault {
+		t.Fatal("Expected default source, but got", source)
+	}
+	if err := fs.Parse([]string{"-name", "direct"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if source, _ := SourceOf(fs, "name"); source.Kind != SourceCommandLine {
+		t.Fatal("Expected command line source, but got", source)
+	}
+}
+
+func TestWriteSources(t *testing.T) {
+	var s = struct {
+		Verbose bool   `flag:"verbose,false,Verbose output."`
+		Name    string `flag:"name,default,The name."`
+	}{}
+	fs := flag.NewFlagSet("writesources", flag.ContinueOnError)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-verbose"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var b strings.Builder
+	if err := WriteSources(&b, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := "-verbose=true  command line\n-name=default  default\n"
+	if b.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", b.String())
+	}
+}
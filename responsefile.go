@@ -0,0 +1,251 @@
+package flagtag
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ExpandResponseFiles expands response files in the provided argument slice.
+// Any argument of the form '@path' is replaced with the arguments contained
+// in the file at 'path'. Arguments following the '--' terminator are left
+// untouched.
+//
+// The response file format is similar to that of a shell:
+//   - arguments are separated by whitespace,
+//   - single quotes preserve the literal value of all enclosed characters,
+//   - double quotes preserve all enclosed characters, except for '\' which
+//     escapes '"' and '\',
+//   - outside of quotes a '\' escapes the next character,
+//   - '#' at the start of an argument starts a comment that runs until the end
+//     of the line.
+//
+// Response files may refer to other response files by means of an unquoted
+// '@path' argument. Relative paths of such nested response files are resolved
+// against the directory of the including file. Cycles are detected and
+// reported as an error.
+//
+// Errors concerning the content of response files are of type
+// ErrResponseFile.
+func ExpandResponseFiles(args []string) ([]string, error) {
+	result, _, err := expandResponseFiles(args, nil)
+	return result, err
+}
+
+// expandResponseFiles expands response files like ExpandResponseFiles. In
+// addition, the origin of every resulting argument is returned. If literal is
+// not nil, it is called with the preceding argument to determine whether an
+// argument must be taken literally instead of being expanded.
+func expandResponseFiles(args []string, literal func(previous string) bool) ([]string, []argOrigin, error) {
+	var e = expander{literal: literal}
+	for _, arg := range args {
+		if e.terminated || len(arg) < 2 || arg[0] != '@' || e.literalNext() {
+			e.add(arg, argOrigin{})
+			continue
+		}
+		if err := e.expand("", 0, arg[1:]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return e.result, e.origins, nil
+}
+
+// argOrigin is the origin of an argument.
+type argOrigin struct {
+	// file is the response file that contained the argument, or empty if
+	// the argument was provided directly.
+	file string
+	line int
+}
+
+// expander contains the state of the expansion of response files.
+type expander struct {
+	result     []string
+	origins    []argOrigin
+	stack      []string
+	terminated bool
+	// literal determines whether the argument following the provided
+	// argument is taken literally.
+	literal func(previous string) bool
+}
+
+// literalNext checks whether the next argument must be taken literally.
+func (e *expander) literalNext() bool {
+	return e.literal != nil && len(e.result) > 0 && e.literal(e.result[len(e.result)-1])
+}
+
+// add adds an argument to the result.
+func (e *expander) add(arg string, origin argOrigin) {
+	if arg == "--" {
+		e.terminated = true
+	}
+	e.result = append(e.result, arg)
+	e.origins = append(e.origins, origin)
+}
+
+// expand expands the response file at the provided path. The parent and line
+// arguments identify the location at which the response file is referenced.
+// parent is empty in case the response file was referenced from the argument
+// slice itself.
+func (e *expander) expand(parent string, line int, path string) error {
+	if parent != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(parent), path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return &ErrResponseFile{File: parent, Line: line, Err: err}
+	}
+	for i, visited := range e.stack {
+		if visited == abs {
+			cycle := append(append([]string{}, e.stack[i:]...), abs)
+			return &ErrResponseFile{File: parent, Line: line, Err: errors.New("response file cycle: " + strings.Join(cycle, " -> "))}
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return &ErrResponseFile{File: parent, Line: line, Err: err}
+	}
+	tokens, err := tokenizeResponseFile(path, string(content))
+	if err != nil {
+		return err
+	}
+	e.stack = append(e.stack, abs)
+	defer func() { e.stack = e.stack[:len(e.stack)-1] }()
+	for _, token := range tokens {
+		if !e.terminated && token.reference && !e.literalNext() {
+			if err := e.expand(path, token.line, token.value[1:]); err != nil {
+				return err
+			}
+			continue
+		}
+		e.add(token.value, argOrigin{file: path, line: token.line})
+	}
+	return nil
+}
+
+// responseToken is a single argument read from a response file.
+type responseToken struct {
+	value string
+	line  int
+	// reference indicates that the token is an unquoted reference to another
+	// response file.
+	reference bool
+}
+
+// tokenizeResponseFile splits the content of a response file into separate
+// arguments.
+func tokenizeResponseFile(path string, content string) ([]responseToken, error) {
+	var tokens []responseToken
+	var current []byte
+	var inToken, quoted bool
+	var line, tokenLine, quoteLine = 1, 0, 0
+	var quote byte
+	finish := func() {
+		if inToken {
+			value := string(current)
+			reference := !quoted && len(value) > 1 && value[0] == '@'
+			tokens = append(tokens, responseToken{value: value, line: tokenLine, reference: reference})
+		}
+		current = current[:0]
+		inToken, quoted = false, false
+	}
+	start := func() {
+		if !inToken {
+			inToken = true
+			tokenLine = line
+		}
+	}
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current = append(current, c)
+			}
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else if c == '\\' && i+1 < len(content) && (content[i+1] == '"' || content[i+1] == '\\') {
+				i++
+				current = append(current, content[i])
+			} else {
+				current = append(current, c)
+			}
+		case c == '\'' || c == '"':
+			start()
+			if len(current) == 0 {
+				// Only a quote at the start of an argument prevents it from
+				// being interpreted as a response file reference.
+				quoted = true
+			}
+			quote = c
+			quoteLine = line
+		case c == '\\':
+			if i+1 >= len(content) {
+				return nil, &ErrResponseFile{File: path, Line: line, Err: errors.New("unexpected end of file after escape character")}
+			}
+			i++
+			if content[i] == '\n' {
+				// escaped newline: line continuation
+				line++
+				continue
+			}
+			start()
+			if len(current) == 0 {
+				quoted = true
+			}
+			current = append(current, content[i])
+		case c == '#' && !inToken:
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			finish()
+		default:
+			start()
+			current = append(current, c)
+		}
+		if c == '\n' {
+			line++
+		}
+	}
+	if quote != 0 {
+		return nil, &ErrResponseFile{File: path, Line: quoteLine, Err: errors.New("unterminated quoted string")}
+	}
+	finish()
+	return tokens, nil
+}
+
+// ErrResponseFile is an error type for problems encountered while expanding
+// response files.
+type ErrResponseFile struct {
+	// File is the response file in which the problem occurred. File is empty
+	// if the problem concerns a response file referenced directly from the
+	// arguments.
+	File string
+	// Line is the line number (starting at 1) of the problem. Line is 0 if
+	// no line information is available.
+	Line int
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the error including file and line position.
+func (e *ErrResponseFile) Error() string {
+	if e.File == "" {
+		return e.Err.Error()
+	}
+	if e.Line == 0 {
+		return e.File + ": " + e.Err.Error()
+	}
+	return e.File + ":" + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrResponseFile) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,165 @@
+package flagtag
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeResponseFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal("Failed to write response file:", err)
+	}
+	return path
+}
+
+func TestExpandResponseFilesNoReferences(t *testing.T) {
+	args := []string{"-a", "b", "@", "c"}
+	result, err := ExpandResponseFiles(args)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(result, args) {
+		t.Fatal("Expected arguments to be left untouched, but got", result)
+	}
+}
+
+func TestExpandResponseFilesQuoting(t *testing.T) {
+	dir := t.TempDir()
+	path := writeResponseFile(t, dir, "args.txt", `# leading comment
+-name 'hello world' "say \"hi\"" escaped\ space
+-empty '' # trailing comment
+"@not-a-reference" a#b \
+  continued
+`)
+	result, err := ExpandResponseFiles([]string{"-first", "@" + path, "-last"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []string{"-first", "-name", "hello world", `say "hi"`, "escaped space", "-empty", "", "@not-a-reference", "a#b", "continued", "-last"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("Expected %q, but got %q", expected, result)
+	}
+}
+
+func TestExpandResponseFilesNested(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeResponseFile(t, filepath.Join(dir, "sub"), "inner.txt", "-inner 2")
+	outer := writeResponseFile(t, dir, "outer.txt", "-outer 1\n@sub/inner.txt\n-after 3")
+	result, err := ExpandResponseFiles([]string{"@" + outer})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []string{"-outer", "1", "-inner", "2", "-after", "3"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("Expected %q, but got %q", expected, result)
+	}
+}
+
+func TestExpandResponseFilesCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := writeResponseFile(t, dir, "a.txt", "-a\n@b.txt")
+	writeResponseFile(t, dir, "b.txt", "-b\n\n@a.txt")
+	_, err := ExpandResponseFiles([]string{"@" + a})
+	if err == nil {
+		t.Fatal("Expected an error because of the cycle.")
+	}
+	respErr, ok := err.(*ErrResponseFile)
+	if !ok {
+		t.Fatal("Expected error of type ErrResponseFile, but got", err)
+	}
+	if filepath.Base(respErr.File) != "b.txt" || respErr.Line != 3 {
+		t.Fatal("Expected cycle to be reported at b.txt:3, but got", err)
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Fatal("Expected error to mention the cycle, but got", err)
+	}
+}
+
+func TestExpandResponseFilesUnterminatedQuote(t *testing.T) {
+	dir := t.TempDir()
+	path := writeResponseFile(t, dir, "args.txt", "-a\n-b 'unterminated\nvalue")
+	_, err := ExpandResponseFiles([]string{"@" + path})
+	if err == nil {
+		t.Fatal("Expected an error because of the unterminated quote.")
+	}
+	if !strings.HasSuffix(err.Error(), "args.txt:2: unterminated quoted string") {
+		t.Fatal("Expected error at line 2, but got", err)
+	}
+}
+
+func TestExpandResponseFilesMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeResponseFile(t, dir, "args.txt", "-a\n@missing.txt")
+	_, err := ExpandResponseFiles([]string{"@" + path})
+	if err == nil {
+		t.Fatal("Expected an error because of the missing file.")
+	}
+	if respErr, ok := err.(*ErrResponseFile); !ok || respErr.Line != 2 || !os.IsNotExist(respErr.Err) {
+		t.Fatal("Expected not-exist error at line 2, but got", err)
+	}
+}
+
+func TestExpandResponseFilesTerminator(t *testing.T) {
+	dir := t.TempDir()
+	path := writeResponseFile(t, dir, "args.txt", "-a -- @other.txt")
+	result, err := ExpandResponseFiles([]string{"@" + path, "@" + path})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := []string{"-a", "--", "@other.txt", "@" + path}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("Expected %q, but got %q", expected, result)
+	}
+}
+
+func TestParseWithResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := writeResponseFile(t, dir, "args.txt", "-name 'response file'")
+	var s = struct {
+		Name  string `flag:"name,,The name."`
+		Times int    `flag:"times,1,The times."`
+	}{}
+	fs := flag.NewFlagSet("responsefiles", flag.ContinueOnError)
+	EnableResponseFiles(fs)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"@" + path, "-times", "3"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.Name != "response file" || s.Times != 3 {
+		t.Fatal("Expected values from response file and arguments, but got", s)
+	}
+}
+
+func TestParseWithoutResponseFiles(t *testing.T) {
+	var s = struct {
+		Name string `flag:"name,,The name."`
+	}{}
+	fs := flag.NewFlagSet("noresponsefiles", flag.ContinueOnError)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-name", "@args.txt"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.Name != "@args.txt" {
+		t.Fatal("Expected response file reference to be left untouched, but got", s.Name)
+	}
+}
+
+func TestParseWithResponseFilesError(t *testing.T) {
+	var s = struct {
+		Name string `flag:"name,,The name."`
+	}{}
+	fs := flag.NewFlagSet("responsefileserror", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	EnableResponseFiles(fs)
+	err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"@" + filepath.Join(t.TempDir(), "missing.txt")})
+	if _, ok := err.(*ErrResponseFile); !ok {
+		t.Fatal("Expected error of type ErrResponseFile, but got", err)
+	}
+}
@@ -0,0 +1,157 @@
+package flagtag
+
+import (
+	"errors"
+	"flag"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// unitBytes is the unit for integer fields that hold a quantity of bytes.
+const unitBytes = "bytes"
+
+// sizeUnit is a unit of a quantity of bytes.
+type sizeUnit struct {
+	suffix     string
+	multiplier uint64
+}
+
+var (
+	// iecUnits are the binary units, from large to small.
+	iecUnits = []sizeUnit{{"EiB", 1 << 60}, {"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}}
+	// siUnits are the decimal units, from large to small.
+	siUnits = []sizeUnit{{"EB", 1e18}, {"PB", 1e15}, {"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"kB", 1e3}}
+)
+
+// sizeMultipliers contains the multiplier by lower case suffix. Both the unit
+// symbols and their abbreviations without 'B' are accepted, e.g. 'MiB', 'Mi',
+// 'MB' and 'M'.
+var sizeMultipliers = func() map[string]uint64 {
+	var multipliers = map[string]uint64{"": 1, "b": 1}
+	for _, unit := range append(append([]sizeUnit(nil), iecUnits...), siUnits...) {
+		var suffix = strings.ToLower(unit.suffix)
+		multipliers[suffix] = unit.multiplier
+		multipliers[strings.TrimSuffix(suffix, "b")] = unit.multiplier
+	}
+	return multipliers
+}()
+
+// registerSizeFlag registers a flag for an integer field with the 'unit' flag
+// option. Values are quantities of bytes with an optional SI (kB, MB, ...) or
+// IEC (KiB, MiB, ...) suffix, e.g. '512MiB' or '1.5G'.
+func registerSizeFlag(fieldName string, fieldValue reflect.Value, tag *flagTag, flagset *flag.FlagSet) error {
+	if tag.Options.Unit != unitBytes {
+		return errors.New("field '" + fieldName + "' (tag '" + tag.Name + "'): unsupported unit '" + tag.Options.Unit + "'")
+	}
+	switch fieldValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return errors.New("field '" + fieldName + "' (tag '" + tag.Name + "'): flag option 'unit' requires an integer field")
+	}
+	var value = &sizeValue{field: fieldValue}
+	if tag.DefaultValue != "" {
+		if err := value.Set(tag.DefaultValue); err != nil {
+			return &ErrInvalidDefault{fieldName, tag.Name, err}
+		}
+	}
+	flagset.Var(value, tag.Name, tag.Description)
+	return nil
+}
+
+// parseSize parses a quantity of bytes.
+func parseSize(text string) (*big.Int, error) {
+	var trimmed = strings.TrimSpace(text)
+	var end = strings.IndexFunc(trimmed, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+')
+	})
+	if end < 0 {
+		end = len(trimmed)
+	}
+	multiplier, ok := sizeMultipliers[strings.ToLower(strings.TrimSpace(trimmed[end:]))]
+	if !ok {
+		return nil, errors.New("invalid size '" + text + "': unknown unit")
+	}
+	quantity, ok := new(big.Rat).SetString(trimmed[:end])
+	if !ok || end == 0 {
+		return nil, errors.New("invalid size '" + text + "'")
+	}
+	quantity.Mul(quantity, new(big.Rat).SetInt(new(big.Int).SetUint64(multiplier)))
+	if !quantity.IsInt() {
+		return nil, errors.New("invalid size '" + text + "': not a whole number of bytes")
+	}
+	return quantity.Num(), nil
+}
+
+// formatSize formats a quantity of bytes using the unit that represents it
+// exactly with the smallest number, preferring IEC units over SI units.
+func formatSize(size *big.Int) string {
+	var best = size.String()
+	var bestNumber = new(big.Int).Abs(size)
+	for _, units := range [][]sizeUnit{iecUnits, siUnits} {
+		for _, unit := range units {
+			quotient, remainder := new(big.Int).QuoRem(size, new(big.Int).SetUint64(unit.multiplier), new(big.Int))
+			if remainder.Sign() != 0 || quotient.Sign() == 0 {
+				continue
+			}
+			if new(big.Int).Abs(quotient).Cmp(bestNumber) < 0 {
+				best, bestNumber = quotient.String()+unit.suffix, new(big.Int).Abs(quotient)
+			}
+			break
+		}
+	}
+	return best
+}
+
+// sizeValue is the flag value of an integer field that holds a quantity of
+// bytes.
+type sizeValue struct {
+	field reflect.Value
+}
+
+func (v *sizeValue) String() string {
+	if !v.field.IsValid() {
+		// zero value, as used to determine whether the default is zero
+		return "0"
+	}
+	return formatSize(v.size())
+}
+
+func (v *sizeValue) Set(text string) error {
+	size, err := parseSize(text)
+	if err != nil {
+		return err
+	}
+	switch v.field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if size.Sign() < 0 || !size.IsUint64() || v.field.OverflowUint(size.Uint64()) {
+			return errors.New("size '" + text + "' out of range")
+		}
+		v.field.SetUint(size.Uint64())
+	default:
+		if !size.IsInt64() || v.field.OverflowInt(size.Int64()) {
+			return errors.New("size '" + text + "' out of range")
+		}
+		v.field.SetInt(size.Int64())
+	}
+	return nil
+}
+
+// size returns the field's value.
+func (v *sizeValue) size() *big.Int {
+	switch v.field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.field.Uint())
+	default:
+		return big.NewInt(v.field.Int())
+	}
+}
+
+func (v *sizeValue) typeName() string {
+	return "size"
+}
+
+func (v *sizeValue) Get() interface{} {
+	return v.field.Interface()
+}
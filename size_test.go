@@ -0,0 +1,128 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"math/big"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	var testset = []struct {
+		text     string
+		expected int64
+	}{
+		{"0", 0},
+		{"123", 123},
+		{"123B", 123},
+		{"2k", 2000},
+		{"2kB", 2000},
+		{"2G", 2000000000},
+		{"2 GB", 2000000000},
+		{"512MiB", 512 << 20},
+		{"512mi", 512 << 20},
+		{"1.5KiB", 1536},
+		{"1.5G", 1500000000},
+		{"-1K", -1000},
+	}
+	for _, test := range testset {
+		size, err := parseSize(test.text)
+		if err != nil {
+			t.Error("Unexpected error for", test.text, ":", err)
+			continue
+		}
+		if size.Cmp(big.NewInt(test.expected)) != 0 {
+			t.Error("Unexpected size for", test.text, ":", size)
+		}
+	}
+}
+
+func TestParseSizeInvalid(t *testing.T) {
+	for _, text := range []string{"", "MiB", "12XB", "1.5", "0.1KiB", "1..2K"} {
+		if _, err := parseSize(text); err == nil {
+			t.Error("Expected an error for", text)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	var testset = []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0"},
+		{123, "123"},
+		{1024, "1KiB"},
+		{2000, "2kB"},
+		{512 << 20, "512MiB"},
+		{1536, "1536"},
+		{1024000, "1000KiB"},
+		{-3 << 30, "-3GiB"},
+	}
+	for _, test := range testset {
+		if text := formatSize(big.NewInt(test.size)); text != test.expected {
+			t.Error("Unexpected text for", test.size, ":", text)
+		}
+	}
+}
+
+func TestSizeFlag(t *testing.T) {
+	var s = struct {
+		Cache  int64  `flag:"cache,64MiB,Cache size." flagopt:"unit=bytes"`
+		Upload uint32 `flag:"upload,,Upload limit." flagopt:"unit=bytes"`
+		Chunk  int    `flag:"chunk,4096,Chunk size." flagopt:"unit=bytes"`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("size", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-upload", "2G"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.Cache != 64<<20 || s.Upload != 2000000000 || s.Chunk != 4096 {
+		t.Fatal("Unexpected values:", s)
+	}
+	writeDefaults(&output, fs, 80)
+	expected := "  -cache size\n        Cache size. (default 64MiB)\n  -upload size\n        Upload limit.\n  -chunk size\n        Chunk size. (default 4KiB)\n"
+	if output.String() != expected {
+		t.Fatal("Unexpected usage:", output.String())
+	}
+}
+
+func TestSizeFlagOutOfRange(t *testing.T) {
+	var testset = [][]string{
+		{"-small", "256"},
+		{"-small", "-1"},
+		{"-large", "8EiB"},
+	}
+	for _, args := range testset {
+		var s = struct {
+			Small uint8 `flag:"small,,Small." flagopt:"unit=bytes"`
+			Large int64 `flag:"large,,Large." flagopt:"unit=bytes"`
+		}{}
+		fs := flag.NewFlagSet("sizerange", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if ConfigureFlagsetAndParseArgs(&s, fs, args) == nil {
+			t.Error("Expected an error for", args)
+		}
+	}
+}
+
+func TestSizeFlagInvalidOptions(t *testing.T) {
+	var testset = []interface{}{
+		&struct {
+			V int `flag:"v,,Value." flagopt:"unit=seconds"`
+		}{},
+		&struct {
+			V float64 `flag:"v,,Value." flagopt:"unit=bytes"`
+		}{},
+		&struct {
+			V int `flag:"v,1X,Value." flagopt:"unit=bytes"`
+		}{},
+	}
+	for _, config := range testset {
+		if ConfigureFlagset(config, flag.NewFlagSet("sizeoptions", flag.ContinueOnError)) == nil {
+			t.Errorf("Expected an error for %T", config)
+		}
+	}
+}
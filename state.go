@@ -0,0 +1,245 @@
+package flagtag
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// flagsetState contains the flagtag-specific settings for a single flag set.
+type flagsetState struct {
+	responseFiles bool
+	abbreviations bool
+	completion    bool
+	warnings      io.Writer
+	// stdinFlag is the name of the flag that read its value from standard
+	// input during parsing, if any.
+	stdinFlag string
+	// flags contains the records of the flags registered by flagtag, in order
+	// of registration.
+	flags []*flagRecord
+}
+
+// flagRecord records the details of a flag registered by flagtag.
+type flagRecord struct {
+	name string
+	// field is the path of the struct field relative to the config value,
+	// e.g. 'Inner.Value'.
+	field string
+	// group is the name of the group the flag belongs to, or empty if the
+	// flag does not belong to a group.
+	group string
+	// parents contains the names of the enclosing struct fields, excluding
+	// embedded fields.
+	parents []string
+	tag     flagTag
+	// typ is the declared type of the struct field.
+	typ reflect.Type
+	// declared is the struct field's value as declared, i.e. before pointers
+	// and interfaces are unwrapped.
+	declared reflect.Value
+	// value is the struct field's value as it is used for the flag.
+	value reflect.Value
+	// source is the source of the flag's value, as far as it is known to
+	// flagtag. Flags that are set directly through the flag set have the
+	// default source.
+	source Source
+}
+
+// states keeps track of the flagtag-specific state of the flag sets that were
+// configured by flagtag or for which flagtag settings were made. The state
+// keeps the flag set and its config value alive until it is released with
+// Release.
+var states = struct {
+	sync.Mutex
+	m map[*flag.FlagSet]*flagsetState
+}{m: make(map[*flag.FlagSet]*flagsetState)}
+
+// stateOf returns the flagtag state for the provided flag set. A new state is
+// created if none exists yet. stateOf is used by the functions that configure
+// the flag set, other functions use lookupState.
+func stateOf(flagset *flag.FlagSet) *flagsetState {
+	states.Lock()
+	defer states.Unlock()
+	state, ok := states.m[flagset]
+	if !ok {
+		state = &flagsetState{}
+		states.m[flagset] = state
+	}
+	return state
+}
+
+// lookupState returns the flagtag state for the provided flag set. If none
+// exists, an empty state is returned without registering it, such that flag
+// sets that were never configured by flagtag do not accumulate state.
+func lookupState(flagset *flag.FlagSet) *flagsetState {
+	states.Lock()
+	defer states.Unlock()
+	if state, ok := states.m[flagset]; ok {
+		return state
+	}
+	return &flagsetState{}
+}
+
+// add adds the record of a registered flag.
+func (s *flagsetState) add(record *flagRecord) {
+	states.Lock()
+	defer states.Unlock()
+	s.flags = append(s.flags, record)
+}
+
+// records returns a copy of the records of all registered flags.
+func (s *flagsetState) records() []*flagRecord {
+	states.Lock()
+	defer states.Unlock()
+	return append([]*flagRecord(nil), s.flags...)
+}
+
+// record returns the record of the flag with the provided name, or nil if no
+// such flag was registered by flagtag.
+func (s *flagsetState) record(name string) *flagRecord {
+	states.Lock()
+	defer states.Unlock()
+	for _, record := range s.flags {
+		if record.name == name {
+			return record
+		}
+	}
+	return nil
+}
+
+// warningOutput returns the writer to which warnings are written.
+func (s *flagsetState) warningOutput(flagset *flag.FlagSet) io.Writer {
+	states.Lock()
+	defer states.Unlock()
+	if s.warnings == nil {
+		return flagset.Output()
+	}
+	return s.warnings
+}
+
+// alias returns the record of the flag for which the provided name is a
+// deprecated alias, or nil if the name is not an alias.
+func (s *flagsetState) alias(name string) *flagRecord {
+	states.Lock()
+	defer states.Unlock()
+	for _, record := range s.flags {
+		for _, alias := range record.tag.Options.Was {
+			if alias == name {
+				return record
+			}
+		}
+	}
+	return nil
+}
+
+// fileRecord returns the record of the flag for which the provided name is the
+// companion flag that reads the value from a file, or nil if the name is not
+// such a flag. See the 'file' flag option.
+func (s *flagsetState) fileRecord(name string) *flagRecord {
+	states.Lock()
+	defer states.Unlock()
+	for _, record := range s.flags {
+		if record.tag.Options.File && fileFlagName(record.name) == name {
+			return record
+		}
+	}
+	return nil
+}
+
+// consumeStdin claims standard input for the named flag. An error is returned
+// if standard input was already read by a flag.
+func (s *flagsetState) consumeStdin(name string) error {
+	states.Lock()
+	defer states.Unlock()
+	if s.stdinFlag != "" {
+		return errors.New("standard input is already read by flag -" + s.stdinFlag)
+	}
+	s.stdinFlag = name
+	return nil
+}
+
+// resetStdin releases standard input such that it can be read again by the
+// next parse.
+func (s *flagsetState) resetStdin() {
+	states.Lock()
+	defer states.Unlock()
+	s.stdinFlag = ""
+}
+
+// setSource sets the source of the flag's value.
+func (s *flagsetState) setSource(record *flagRecord, source Source) {
+	states.Lock()
+	defer states.Unlock()
+	record.source = source
+}
+
+// resetSources resets the sources of all flags to the default source.
+func (s *flagsetState) resetSources() {
+	states.Lock()
+	defer states.Unlock()
+	for _, record := range s.flags {
+		record.source = Source{}
+	}
+}
+
+// recordedSource returns the source of the flag's value as recorded by flagtag.
+func (s *flagsetState) recordedSource(record *flagRecord) Source {
+	states.Lock()
+	defer states.Unlock()
+	return record.source
+}
+
+// Release releases the flagtag-specific state of the provided flag set, such
+// as the records of the flags that were configured and the settings made with
+// the Enable functions. flagtag keeps this state, and with it the flag set and
+// its config value, for as long as the program runs, so programs that create
+// flag sets repeatedly should release each flag set once it is no longer used.
+// Afterwards, the functions of this package treat the flags of the flag set as
+// if they were not configured by flagtag. The flag values themselves keep
+// their behavior, e.g. secret values remain redacted.
+func Release(flagset *flag.FlagSet) {
+	states.Lock()
+	defer states.Unlock()
+	delete(states.m, flagset)
+}
+
+// EnableResponseFiles enables the expansion of response files for the
+// provided flag set. Expansion is performed by the parse functions of this
+// package, i.e. ConfigureFlagsetAndParseArgs and friends. See
+// ExpandResponseFiles for details on the response file format.
+func EnableResponseFiles(flagset *flag.FlagSet) {
+	stateOf(flagset).responseFiles = true
+}
+
+// EnableAbbreviations enables the use of unambiguous prefixes of flag names for
+// the provided flag set, e.g. '-verb' for '-verbose'. A prefix is ambiguous if
+// it matches more than one of the flags defined in the flag set, in which case
+// parsing fails with an error of type ErrAmbiguousFlag. Abbreviations are
+// resolved by the parse functions of this package, i.e.
+// ConfigureFlagsetAndParseArgs and friends.
+func EnableAbbreviations(flagset *flag.FlagSet) {
+	stateOf(flagset).abbreviations = true
+}
+
+// SetWarningOutput sets the destination for warnings concerning the provided
+// flag set, such as the use of deprecated flags. If w is nil, warnings are
+// written to the flag set's output.
+func SetWarningOutput(flagset *flag.FlagSet, w io.Writer) {
+	var state = stateOf(flagset)
+	states.Lock()
+	defer states.Unlock()
+	state.warnings = w
+}
+
+// EnableCompletion enables dynamic completion for the provided flag set. If
+// the first argument is '__complete', the parse functions of this package
+// print the completion candidates for the remaining arguments to standard
+// output and exit the program. See Complete for details. Completion scripts
+// generated for the flag set delegate the completion of flag values that
+// implement Completer to the program.
+func EnableCompletion(flagset *flag.FlagSet) {
+	stateOf(flagset).completion = true
+}
@@ -0,0 +1,55 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+)
+
+// hasState checks whether flagtag holds state for the flag set.
+func hasState(flagset *flag.FlagSet) bool {
+	states.Lock()
+	defer states.Unlock()
+	_, ok := states.m[flagset]
+	return ok
+}
+
+func TestNoStateWithoutConfiguration(t *testing.T) {
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("nostate", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	fs.Bool("verbose", false, "Verbose output.")
+	PrintDefaults(fs)
+	Describe(fs)
+	FormatArgs(fs, true)
+	SourceOf(fs, "verbose")
+	if err := WriteSources(&output, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if hasState(fs) {
+		t.Fatal("Expected no state for a flag set that was not configured by flagtag.")
+	}
+}
+
+func TestRelease(t *testing.T) {
+	var s = struct {
+		V int `flag:"v,1,Value." flagopt:"hidden"`
+	}{}
+	fs := flag.NewFlagSet("release", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !hasState(fs) {
+		t.Fatal("Expected state for the flag set.")
+	}
+	Release(fs)
+	if hasState(fs) {
+		t.Fatal("Expected state to be released.")
+	}
+	if len(Describe(fs)) != 0 {
+		t.Fatal("Expected no flags configured by flagtag after release.")
+	}
+	if err := fs.Parse([]string{"-v", "3"}); err != nil || s.V != 3 {
+		t.Fatal("Expected flag to remain usable after release.")
+	}
+}
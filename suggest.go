@@ -0,0 +1,132 @@
+package flagtag
+
+import (
+	"flag"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of suggestions offered for an unknown
+// flag.
+const maxSuggestions = 3
+
+// checkUnknownFlags checks the flag arguments in args for flags that are not
+// defined in the flag set. The first unknown flag is reported as an error of
+// type ErrUnknownFlag.
+func checkUnknownFlags(flagset *flag.FlagSet, args []string) error {
+	return visitFlagArgs(flagset, args, func(index int, name string) (string, error) {
+		if flagset.Lookup(name) != nil || isHelpFlag(flagset, name) {
+			return name, nil
+		}
+		return "", &ErrUnknownFlag{Name: name, Suggestions: suggestFlags(flagset, name)}
+	})
+}
+
+// suggestFlags returns the names of the flags in the flag set that closely
+// resemble the provided name, ordered from most to least similar. Similarity
+// is determined by the edit distance between the names, in which swapped
+// adjacent letters count as a single edit. Flags of which the provided name is
+// a prefix are considered similar as well. Former names of flags are matched
+// too, in which case the current name of the flag is suggested. Hidden flags
+// are never suggested.
+func suggestFlags(flagset *flag.FlagSet, name string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	var threshold = len(name) / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+	var suggestions []suggestion
+	// index contains the position of every suggested name in suggestions,
+	// such that a flag matched by multiple names is suggested only once
+	var index = make(map[string]int)
+	var state = lookupState(flagset)
+	flagset.VisitAll(func(f *flag.Flag) {
+		var suggested = f.Name
+		var record = state.record(f.Name)
+		if record == nil {
+			if record = state.alias(f.Name); record != nil {
+				suggested = record.name
+			} else {
+				record = state.fileRecord(f.Name)
+			}
+		}
+		if record != nil && record.tag.Options.Hidden {
+			return
+		}
+		distance := editDistance(name, f.Name)
+		if distance > threshold && !strings.HasPrefix(f.Name, name) {
+			return
+		}
+		if i, ok := index[suggested]; ok {
+			suggestions[i].distance = min(suggestions[i].distance, distance)
+			return
+		}
+		index[suggested] = len(suggestions)
+		suggestions = append(suggestions, suggestion{suggested, distance})
+	})
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	var names []string
+	for _, s := range suggestions {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// editDistance calculates the optimal string alignment distance between a and
+// b: the Levenshtein distance extended with transpositions of adjacent
+// characters, such that a swapped pair of letters counts as a single edit.
+func editDistance(a, b string) int {
+	var beforePrevious = make([]int, len(b)+1)
+	var previous = make([]int, len(b)+1)
+	var current = make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], beforePrevious[j-2]+1)
+			}
+		}
+		beforePrevious, previous, current = previous, current, beforePrevious
+	}
+	return previous[len(b)]
+}
+
+// ErrUnknownFlag is an error type for the case where a flag is provided that is
+// not defined.
+type ErrUnknownFlag struct {
+	// Name is the name of the unknown flag as provided.
+	Name string
+	// Suggestions contains the names of defined flags that closely resemble
+	// the unknown flag, ordered from most to least similar.
+	Suggestions []string
+}
+
+// Error returns the error including suggestions for flags that may have been
+// meant instead.
+func (e *ErrUnknownFlag) Error() string {
+	var msg = "flag provided but not defined: -" + e.Name
+	switch len(e.Suggestions) {
+	case 0:
+		return msg
+	case 1:
+		return msg + " (did you mean -" + e.Suggestions[0] + "?)"
+	default:
+		return msg + " (did you mean one of -" + strings.Join(e.Suggestions, ", -") + "?)"
+	}
+}
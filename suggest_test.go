@@ -0,0 +1,130 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	var testset = []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"verbose", "verbose", 0},
+		{"verbos", "verbose", 1},
+		{"vrebose", "verbose", 1},
+		{"nmae", "name", 1},
+		{"ab", "ba", 1},
+		{"ca", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+	for nr, test := range testset {
+		if d := editDistance(test.a, test.b); d != test.distance {
+			t.Error("Test entry", nr, "failed with distance", d)
+		}
+	}
+}
+
+func TestSuggestFlags(t *testing.T) {
+	fs := flag.NewFlagSet("suggest", flag.ContinueOnError)
+	fs.Bool("verbose", false, "")
+	fs.Bool("version", false, "")
+	fs.String("output", "", "")
+	fs.String("name", "", "")
+	if s := suggestFlags(fs, "verbos"); !reflect.DeepEqual(s, []string{"verbose"}) {
+		t.Error("Unexpected suggestions:", s)
+	}
+	if s := suggestFlags(fs, "outptu"); !reflect.DeepEqual(s, []string{"output"}) {
+		t.Error("Unexpected suggestions:", s)
+	}
+	if s := suggestFlags(fs, "nmae"); !reflect.DeepEqual(s, []string{"name"}) {
+		t.Error("Unexpected suggestions:", s)
+	}
+	if s := suggestFlags(fs, "ver"); !reflect.DeepEqual(s, []string{"verbose", "version"}) {
+		t.Error("Unexpected suggestions:", s)
+	}
+	if s := suggestFlags(fs, "out"); !reflect.DeepEqual(s, []string{"output"}) {
+		t.Error("Unexpected suggestions:", s)
+	}
+	if s := suggestFlags(fs, "xyz"); len(s) != 0 {
+		t.Error("Expected no suggestions, but got:", s)
+	}
+}
+
+func TestSuggestFlagsFormerNames(t *testing.T) {
+	var s = struct {
+		Nom     string `flag:"nom,,The name." flagopt:"was=nmae,was=name"`
+		Verbose bool   `flag:"verbose,false,Verbose output."`
+		Debug   bool   `flag:"debug,false,Debug output." flagopt:"hidden,was=dbg"`
+	}{}
+	fs := flag.NewFlagSet("suggestformernames", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var testset = []struct {
+		name     string
+		expected []string
+	}{
+		{"nmea", []string{"nom"}},
+		{"nome", []string{"nom"}},
+		{"dbug", nil},
+	}
+	for _, test := range testset {
+		if s := suggestFlags(fs, test.name); !reflect.DeepEqual(s, test.expected) {
+			t.Error("Unexpected suggestions for", test.name, ":", s)
+		}
+	}
+}
+
+func TestParseUnknownFlag(t *testing.T) {
+	var s = struct {
+		Verbose bool   `flag:"verbose,false,Verbose output."`
+		Name    string `flag:"name,,The name."`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("unknownflag", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-name", "-verbos", "-verbos"})
+	unknown, ok := err.(*ErrUnknownFlag)
+	if !ok {
+		t.Fatal("Expected error of type ErrUnknownFlag, but got", err)
+	}
+	if unknown.Name != "verbos" || !reflect.DeepEqual(unknown.Suggestions, []string{"verbose"}) {
+		t.Fatal("Unexpected unknown flag information:", unknown)
+	}
+	if !strings.HasPrefix(output.String(), "flag provided but not defined: -verbos (did you mean -verbose?)\n") {
+		t.Fatal("Expected suggestion to be printed, but got:", output.String())
+	}
+}
+
+func TestParseUnknownFlagNoSuggestions(t *testing.T) {
+	var s = struct {
+		Verbose bool `flag:"verbose,false,Verbose output."`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("unknownflagnosuggestions", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-unrelated"})
+	if _, ok := err.(*ErrUnknownFlag); !ok {
+		t.Fatal("Expected error of type ErrUnknownFlag, but got", err)
+	}
+	if err.Error() != "flag provided but not defined: -unrelated" {
+		t.Fatal("Unexpected error message:", err.Error())
+	}
+}
+
+func TestParseHelpFlag(t *testing.T) {
+	var s = struct{}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("helpflag", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-help"}); err != flag.ErrHelp {
+		t.Fatal("Expected flag.ErrHelp, but got", err)
+	}
+}
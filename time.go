@@ -0,0 +1,69 @@
+package flagtag
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	// layoutRFC3339 is the default layout of time.Time values.
+	layoutRFC3339 = "rfc3339"
+	// layoutDate is the layout of dates without time.
+	layoutDate = "date"
+	// layoutUnix is the layout of Unix timestamps in seconds.
+	layoutUnix = "unix"
+)
+
+var (
+	// timeType is the type of points in time.
+	timeType = reflect.TypeOf(time.Time{})
+	// locationType is the type of time zones, which are always used by
+	// pointer.
+	locationType = reflect.TypeOf((*time.Location)(nil))
+)
+
+// timeTextType returns the description of time.Time values in the provided
+// layout: 'rfc3339' (the default), 'date', 'unix' or a layout as accepted by
+// time.Parse. Values without time zone are interpreted as UTC. The empty
+// string represents the zero time.
+func timeTextType(layout string) textType {
+	var name = "time"
+	switch layout {
+	case "", layoutRFC3339:
+		layout = time.RFC3339
+	case layoutDate:
+		name = "date"
+		layout = time.DateOnly
+	case layoutUnix:
+		name = "seconds"
+	}
+	return textType{
+		name: name,
+		parse: func(text string) (interface{}, error) {
+			if text == "" {
+				return time.Time{}, nil
+			}
+			if layout == layoutUnix {
+				seconds, err := strconv.ParseInt(text, 10, 64)
+				if err != nil {
+					return nil, errors.New("invalid Unix timestamp '" + text + "'")
+				}
+				return time.Unix(seconds, 0).UTC(), nil
+			}
+			return time.Parse(layout, text)
+		},
+		format: func(value interface{}) string {
+			var t = value.(time.Time)
+			switch {
+			case t.IsZero():
+				return ""
+			case layout == layoutUnix:
+				return strconv.FormatInt(t.Unix(), 10)
+			default:
+				return t.Format(layout)
+			}
+		},
+	}
+}
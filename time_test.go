@@ -0,0 +1,109 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+	"time"
+)
+
+type timeConfig struct {
+	Since    time.Time      `flag:"since,2024-01-02T03:04:05Z,Start time."`
+	Day      time.Time      `flag:"day,,Day." flagopt:"layout=date"`
+	Epoch    time.Time      `flag:"epoch,,Unix timestamp." flagopt:"layout=unix"`
+	Custom   time.Time      `flag:"custom,,Custom layout." flagopt:"layout=02/01/2006 15:04"`
+	Until    *time.Time     `flag:"until,,Optional end time."`
+	Zone     *time.Location `flag:"zone,UTC,Time zone."`
+	Optional *time.Location `flag:"optional-zone,,Optional time zone."`
+}
+
+func TestTimeFlags(t *testing.T) {
+	var s timeConfig
+	fs := flag.NewFlagSet("time", flag.ContinueOnError)
+	args := []string{"-day", "2024-02-29", "-epoch", "86400", "-custom", "31/12/2023 23:59", "-zone", "Europe/Amsterdam"}
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, args); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var testset = []struct {
+		value    time.Time
+		expected time.Time
+	}{
+		{s.Since, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{s.Day, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{s.Epoch, time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{s.Custom, time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC)},
+	}
+	for _, test := range testset {
+		if !test.value.Equal(test.expected) {
+			t.Errorf("Expected %v, but got %v", test.expected, test.value)
+		}
+	}
+	if s.Until != nil {
+		t.Error("Expected optional time to stay nil.")
+	}
+	if s.Zone == nil || s.Zone.String() != "Europe/Amsterdam" {
+		t.Error("Unexpected time zone:", s.Zone)
+	}
+	if s.Optional != nil {
+		t.Error("Expected optional time zone to stay nil.")
+	}
+	if v := fs.Lookup("epoch").Value.String(); v != "86400" {
+		t.Error("Unexpected string representation:", v)
+	}
+}
+
+func TestTimeFlagsFormatArgs(t *testing.T) {
+	var s timeConfig
+	fs := flag.NewFlagSet("timeformat", flag.ContinueOnError)
+	args := []string{"-since", "2020-05-06T07:08:09+02:00", "-day", "2024-02-29", "-until", "2030-01-01T00:00:00Z", "-optional-zone", "Asia/Tokyo"}
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, args); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var copied timeConfig
+	fs2 := flag.NewFlagSet("timeformat2", flag.ContinueOnError)
+	if err := ConfigureFlagsetAndParseArgs(&copied, fs2, FormatArgs(fs, true)); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !copied.Since.Equal(s.Since) || !copied.Day.Equal(s.Day) || copied.Until == nil || !copied.Until.Equal(*s.Until) {
+		t.Fatal("Unexpected values after round trip:", copied)
+	}
+	if copied.Optional == nil || copied.Optional.String() != "Asia/Tokyo" {
+		t.Fatal("Unexpected time zone after round trip:", copied.Optional)
+	}
+}
+
+func TestTimeFlagsInvalidValue(t *testing.T) {
+	var testset = [][]string{
+		{"-since", "2024-01-02"},
+		{"-day", "02-01-2024"},
+		{"-epoch", "soon"},
+		{"-zone", "Nowhere/Special"},
+	}
+	for _, args := range testset {
+		var s timeConfig
+		fs := flag.NewFlagSet("timeinvalid", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		if ConfigureFlagsetAndParseArgs(&s, fs, args) == nil {
+			t.Error("Expected an error for", args)
+		}
+	}
+}
+
+func TestTimeFlagsUsage(t *testing.T) {
+	var s = struct {
+		Since time.Time      `flag:"since,,Start time."`
+		Day   time.Time      `flag:"day,2024-02-29,Day." flagopt:"layout=date"`
+		Zone  *time.Location `flag:"zone,UTC,Time zone."`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("timeusage", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	writeDefaults(&output, fs, 80)
+	expected := "  -since time\n        Start time.\n  -day date\n        Day. (default 2024-02-29)\n  -zone timezone\n        Time zone. (default UTC)\n"
+	if output.String() != expected {
+		t.Fatal("Unexpected usage:", output.String())
+	}
+}
@@ -0,0 +1,261 @@
+package flagtag
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultUsageWidth is the width to which usage information is wrapped if
+	// the COLUMNS environment variable is not set.
+	defaultUsageWidth = 80
+	// minimumUsageWidth is the minimum width to which usage information is
+	// wrapped.
+	minimumUsageWidth = 40
+	// usageIndent is the indentation of flag descriptions.
+	usageIndent = "        "
+)
+
+// Usage returns a usage function for the provided flag set, which prints a
+// header followed by the output of PrintDefaults. The function is intended to
+// be installed as the flag set's Usage function:
+//
+//	flagset.Usage = flagtag.Usage(flagset)
+//
+// Otherwise, the usage information printed on parse errors is that of the flag
+// package, which includes hidden flags and former names of flags.
+func Usage(flagset *flag.FlagSet) func() {
+	return func() {
+		if flagset.Name() == "" {
+			fmt.Fprintf(flagset.Output(), "Usage:\n")
+		} else {
+			fmt.Fprintf(flagset.Output(), "Usage of %s:\n", flagset.Name())
+		}
+		PrintDefaults(flagset)
+	}
+}
+
+// PrintDefaults prints usage information on all flags of the flag set to the
+// flag set's output. In contrast to flag's PrintDefaults, flags configured by
+// flagtag are printed in the order in which they are declared in the config
+// struct. Flags are grouped by the nested struct in which they are declared,
+// or by the group specified with the 'group' flag option. Flags that were not
+// configured by flagtag are printed first in alphabetical order. Flags marked
+// with the 'hidden' flag option and former names of flags are omitted.
+//
+// Descriptions are wrapped to the width indicated by the COLUMNS environment
+// variable, or to 80 columns if it is not set. The size of the terminal is not
+// queried, and COLUMNS is typically not exported by shells, so export it to
+// wrap to the actual width of the terminal. Widths below 40 columns are
+// raised to 40.
+func PrintDefaults(flagset *flag.FlagSet) {
+	writeDefaults(flagset.Output(), flagset, usageWidth())
+}
+
+// usageWidth determines the width to which usage information is wrapped, from
+// the COLUMNS environment variable.
+func usageWidth() int {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || width <= 0 {
+		return defaultUsageWidth
+	}
+	if width < minimumUsageWidth {
+		return minimumUsageWidth
+	}
+	return width
+}
+
+// writeDefaults writes usage information on all flags of the flag set to w,
+// with descriptions wrapped to the specified width.
+func writeDefaults(w io.Writer, flagset *flag.FlagSet, width int) {
+	var b strings.Builder
+	for _, section := range usageSections(flagset) {
+		if section.group != "" {
+			fmt.Fprintf(&b, "\n%s:\n", section.group)
+		}
+		for _, f := range section.flags {
+			writeFlagUsage(&b, flagset, f, width)
+		}
+	}
+	io.WriteString(w, b.String())
+}
+
+// usageSection is a group of flags in the usage information.
+type usageSection struct {
+	group string
+	flags []*flag.Flag
+}
+
+// usageSections divides the flags of the flag set into sections, according to
+// the group they belong to. The section of flags that do not belong to a group
+// comes first. Other sections are ordered by first appearance. Companion flags
+// of the 'file' flag option directly follow the flag they belong to.
+func usageSections(flagset *flag.FlagSet) []*usageSection {
+	var sections = []*usageSection{{}}
+	var recorded = make(map[string]bool)
+	var records = lookupState(flagset).records()
+	for _, record := range records {
+		recorded[record.name] = true
+		for _, alias := range record.tag.Options.Was {
+			recorded[alias] = true
+		}
+		if record.tag.Options.File {
+			recorded[fileFlagName(record.name)] = true
+		}
+	}
+	flagset.VisitAll(func(f *flag.Flag) {
+		if !recorded[f.Name] {
+			sections[0].flags = append(sections[0].flags, f)
+		}
+	})
+	var indices = map[string]int{"": 0}
+	for _, record := range records {
+		f := flagset.Lookup(record.name)
+		if f == nil || record.tag.Options.Hidden {
+			continue
+		}
+		index, ok := indices[record.group]
+		if !ok {
+			index = len(sections)
+			indices[record.group] = index
+			sections = append(sections, &usageSection{group: record.group})
+		}
+		sections[index].flags = append(sections[index].flags, f)
+		if record.tag.Options.File {
+			// companion flag is shown directly after the flag
+			if f := flagset.Lookup(fileFlagName(record.name)); f != nil {
+				sections[index].flags = append(sections[index].flags, f)
+			}
+		}
+	}
+	return sections
+}
+
+// writeFlagUsage writes the usage information of a single flag.
+func writeFlagUsage(b *strings.Builder, flagset *flag.FlagSet, f *flag.Flag, width int) {
+	name, usage := unquoteUsage(flagset, f)
+	b.WriteString("  -")
+	b.WriteString(f.Name)
+	if name != "" {
+		b.WriteString(" ")
+		b.WriteString(name)
+	}
+	b.WriteString("\n")
+	if def, ok := defaultText(f); ok {
+		usage += " (default " + def + ")"
+	}
+	if deprecated := deprecationText(flagset, f); deprecated != "" {
+		usage += " (deprecated: " + deprecated + ")"
+	}
+	for _, line := range wrapText(usage, width-len(usageIndent)) {
+		b.WriteString(usageIndent)
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+// defaultText returns the flag's default value as it is shown in usage
+// information. Default values of string flags are quoted. ok is false if the
+// default value is the zero value or the flag is optional or secret, in which
+// case it is not shown.
+func defaultText(f *flag.Flag) (text string, ok bool) {
+	if isOptionalFlag(f.Value) || secretOf(f.Value) != nil || isZeroValue(f, f.DefValue) {
+		return "", false
+	}
+	if valueTypeName(f.Value) == "string" {
+		return strconv.Quote(f.DefValue), true
+	}
+	return f.DefValue, true
+}
+
+// deprecationText returns the deprecation message of the flag, or an empty
+// string if the flag is not deprecated.
+func deprecationText(flagset *flag.FlagSet, f *flag.Flag) string {
+	if record := lookupState(flagset).record(f.Name); record != nil {
+		return record.tag.Options.Deprecated
+	}
+	return ""
+}
+
+// unquoteUsage extracts the name of the flag's value and the usage description
+// without quotes, like flag.UnquoteUsage. The name specified with the
+// 'metavar' flag option takes precedence over the name that the flag package
+// derives from the usage description or the flag's type.
+func unquoteUsage(flagset *flag.FlagSet, f *flag.Flag) (name string, usage string) {
+	name, usage = flag.UnquoteUsage(&flag.Flag{Usage: f.Usage, Value: unwrapValue(f.Value)})
+	name = typeNameOf(f.Value, name)
+	if record := lookupState(flagset).record(f.Name); record != nil && record.tag.Options.Metavar != "" {
+		name = record.tag.Options.Metavar
+	}
+	return name, usage
+}
+
+// valueTypeName returns the name of the value's type as derived by the flag
+// package, e.g. 'string' or 'int'. Flag values of flagtag that implement
+// typedValue provide the name themselves.
+func valueTypeName(value flag.Value) string {
+	name, _ := flag.UnquoteUsage(&flag.Flag{Value: unwrapValue(value)})
+	return typeNameOf(value, name)
+}
+
+// typedValue is implemented by flag values of flagtag that are not of one of
+// flag's own types, but represent a value of a type known to flag.
+type typedValue interface {
+	typeName() string
+}
+
+// typeNameOf returns the type name of the flag value if it implements
+// typedValue and name is the generic name 'value' that the flag package uses
+// for unknown types. Otherwise name is returned as is.
+func typeNameOf(value flag.Value, name string) string {
+	if typed, ok := unwrapValue(value).(typedValue); ok && name == "value" {
+		return typed.typeName()
+	}
+	return name
+}
+
+// wrapText wraps text into lines of at most width characters. Words that are
+// longer than width are put on a line of their own.
+func wrapText(text string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line == "" {
+			line = word
+		} else {
+			line += " " + word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// isZeroValue determines whether the string represents the zero value for a
+// flag, in the same way as flag's PrintDefaults does.
+func isZeroValue(f *flag.Flag, value string) (zero bool) {
+	defer func() {
+		if recover() != nil {
+			// String method of the zero value panicked, assume non-zero.
+			zero = false
+		}
+	}()
+	var typ = reflect.TypeOf(unwrapValue(f.Value))
+	var z reflect.Value
+	if typ.Kind() == reflect.Ptr {
+		z = reflect.New(typ.Elem())
+	} else {
+		z = reflect.Zero(typ)
+	}
+	return value == z.Interface().(flag.Value).String()
+}
@@ -0,0 +1,118 @@
+package flagtag
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrapText(t *testing.T) {
+	var testset = []struct {
+		text  string
+		width int
+		lines []string
+	}{
+		{"", 10, nil},
+		{"short", 10, []string{"short"}},
+		{"a few words to wrap", 10, []string{"a few", "words to", "wrap"}},
+		{"averyveryverylongword is long", 10, []string{"averyveryverylongword", "is long"}},
+	}
+	for nr, test := range testset {
+		if lines := wrapText(test.text, test.width); !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("Test entry %d failed: %q", nr, lines)
+		}
+	}
+}
+
+func TestWriteDefaults(t *testing.T) {
+	var s = struct {
+		Verbose bool   `flag:"verbose,false,Enable verbose output."`
+		Name    string `flag:"name,world,The name of the person that is greeted by this program."`
+		Server  struct {
+			Port    int           `flag:"port,8080,The port to listen on."`
+			Timeout time.Duration `flag:"timeout,0s,The timeout."`
+		}
+		Debug bool `flag:"debug,true,Enable debugging." flagopt:"group=Diagnostics"`
+		Inner struct {
+			Trace bool `flag:"trace,false,Enable tracing."`
+		} `flagopt:"group=Diagnostics"`
+	}{}
+	fs := flag.NewFlagSet("usage", flag.ContinueOnError)
+	fs.Int("manual", 3, "A manually defined flag.")
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var b strings.Builder
+	writeDefaults(&b, fs, 50)
+	expected := `  -manual int
+        A manually defined flag. (default 3)
+  -verbose
+        Enable verbose output.
+  -name string
+        The name of the person that is greeted by
+        this program. (default "world")
+
+Server:
+  -port int
+        The port to listen on. (default 8080)
+  -timeout duration
+        The timeout.
+
+Diagnostics:
+  -debug
+        Enable debugging. (default true)
+  -trace
+        Enable tracing.
+`
+	if b.String() != expected {
+		t.Fatalf("Unexpected usage output:\n%s", b.String())
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var s = struct {
+		Verbose bool `flag:"verbose,false,Enable verbose output."`
+	}{}
+	var b strings.Builder
+	fs := flag.NewFlagSet("program", flag.ContinueOnError)
+	fs.SetOutput(&b)
+	fs.Usage = Usage(fs)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-help"}); err != flag.ErrHelp {
+		t.Fatal("Expected flag.ErrHelp, but got", err)
+	}
+	if !strings.HasPrefix(b.String(), "Usage of program:\n  -verbose\n") {
+		t.Fatal("Unexpected usage output:", b.String())
+	}
+}
+
+func TestWriteDefaultsPlaceholders(t *testing.T) {
+	var s = struct {
+		Listen  string `flag:"listen,,Listen on {ADDR} for connections."`
+		Backend string `flag:"backend,,Forward requests to the backend." flagopt:"metavar=URL"`
+		Both    string `flag:"both,,Placeholder {name} is overridden." flagopt:"metavar=OVERRIDE"`
+		Quoted  string "flag:\"quoted,,Keeps the back-quoted `value` and {other}.\""
+	}{}
+	fs := flag.NewFlagSet("placeholders", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var b strings.Builder
+	writeDefaults(&b, fs, 80)
+	expected := `  -listen ADDR
+        Listen on ADDR for connections.
+  -backend URL
+        Forward requests to the backend.
+  -both OVERRIDE
+        Placeholder name is overridden.
+  -quoted value
+        Keeps the back-quoted value and {other}.
+`
+	if b.String() != expected {
+		t.Fatalf("Unexpected usage output:\n%s", b.String())
+	}
+	if fs.Lookup("listen").Usage != "Listen on `ADDR` for connections." {
+		t.Fatal("Expected placeholder to be registered in the flag package's format, but got", fs.Lookup("listen").Usage)
+	}
+}
@@ -0,0 +1,283 @@
+package flagtag
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// wrappedValue is implemented by flag values that wrap another flag value in
+// order to add behavior.
+type wrappedValue interface {
+	unwrap() flag.Value
+}
+
+// unwrapValue returns the innermost flag value, i.e. the flag value without
+// any of the wrappers that flagtag may have added.
+func unwrapValue(value flag.Value) flag.Value {
+	for {
+		wrapped, ok := value.(wrappedValue)
+		if !ok || wrapped.unwrap() == nil {
+			return value
+		}
+		value = wrapped.unwrap()
+	}
+}
+
+// registerAliases registers the former names of the flag, as specified with the
+// 'was' flag option, as deprecated aliases of the flag. Aliases write into the
+// same field as the flag itself.
+func registerAliases(flagset *flag.FlagSet, record *flagRecord) {
+	var f = flagset.Lookup(record.name)
+	for _, alias := range record.tag.Options.Was {
+		flagset.Var(&deprecatedValue{Value: f.Value, flagset: flagset, name: alias, message: "use -" + f.Name}, alias, f.Usage)
+		flagset.Lookup(alias).DefValue = f.DefValue
+	}
+}
+
+// redacted is shown in place of the values of secret flags.
+const redacted = "******"
+
+// redactFlagValue wraps the value of the registered flag such that its value
+// is redacted, if the flag is marked with the 'secret' flag option. The
+// default value is removed from the flag, such that it is not shown in usage
+// information, and kept by the wrapper instead. Aliases must be registered
+// afterwards, such that they share the redacted value.
+func redactFlagValue(flagset *flag.FlagSet, record *flagRecord) {
+	if !record.tag.Options.Secret {
+		return
+	}
+	var f = flagset.Lookup(record.name)
+	f.Value = &secretValue{Value: f.Value, defValue: f.DefValue}
+	f.DefValue = ""
+}
+
+// defaultValue returns the flag's default value. In contrast to the flag's
+// DefValue, the actual default value is returned for secret flags.
+func defaultValue(f *flag.Flag) string {
+	if secret := secretOf(f.Value); secret != nil {
+		return secret.defValue
+	}
+	return f.DefValue
+}
+
+// secretOf returns the wrapper of the flag value that redacts it, or nil if
+// the flag is not secret.
+func secretOf(value flag.Value) *secretValue {
+	for {
+		if secret, ok := value.(*secretValue); ok {
+			return secret
+		}
+		wrapped, ok := value.(wrappedValue)
+		if !ok || wrapped.unwrap() == nil {
+			return nil
+		}
+		value = wrapped.unwrap()
+	}
+}
+
+// secretValue wraps the value of a secret flag. Its string representation is
+// redacted, unless the value is empty.
+type secretValue struct {
+	flag.Value
+	// defValue is the actual default value of the flag.
+	defValue string
+}
+
+func (s *secretValue) unwrap() flag.Value {
+	return s.Value
+}
+
+func (s *secretValue) String() string {
+	if s.Value == nil || s.Value.String() == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s *secretValue) IsBoolFlag() bool {
+	return isBoolFlag(s.Value)
+}
+
+func (s *secretValue) Get() interface{} {
+	if getter, ok := s.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return nil
+}
+
+// wrapFlagValue wraps the value of the registered flag as required by the flag
+// options of the record.
+func wrapFlagValue(flagset *flag.FlagSet, record *flagRecord) {
+	var f = flagset.Lookup(record.name)
+	if record.tag.Options.Deprecated != "" {
+		f.Value = &deprecatedValue{Value: f.Value, flagset: flagset, name: f.Name, message: record.tag.Options.Deprecated}
+	}
+}
+
+// deprecatedValue wraps the value of a deprecated flag. A warning is emitted
+// the first time the flag is set.
+type deprecatedValue struct {
+	flag.Value
+	flagset *flag.FlagSet
+	name    string
+	message string
+	warned  bool
+}
+
+func (d *deprecatedValue) unwrap() flag.Value {
+	return d.Value
+}
+
+func (d *deprecatedValue) String() string {
+	if d.Value == nil {
+		return ""
+	}
+	return d.Value.String()
+}
+
+func (d *deprecatedValue) Set(value string) error {
+	if !d.warned {
+		d.warned = true
+		fmt.Fprintf(lookupState(d.flagset).warningOutput(d.flagset), "warning: flag -%s is deprecated: %s\n", d.name, d.message)
+	}
+	return d.Value.Set(value)
+}
+
+func (d *deprecatedValue) IsBoolFlag() bool {
+	return isBoolFlag(d.Value)
+}
+
+func (d *deprecatedValue) Get() interface{} {
+	if getter, ok := d.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return nil
+}
+
+// registerOptionalFlag registers a flag for a nil pointer field. The value that
+// the field points to is allocated only when the flag is set, such that the
+// field stays nil if the flag is not specified. The returned value is a zero
+// value of the pointer's element type, which represents the field's value for
+// introspection.
+func registerOptionalFlag(fieldName string, field reflect.Value, tag *flagTag, flagset *flag.FlagSet) (reflect.Value, error) {
+	var optional = &optionalValue{flagset: flagset, field: field, fieldName: fieldName, tag: *tag}
+	optional.tag.DefaultValue = ""
+	if _, ok := reflect.New(field.Type().Elem()).Interface().(flag.Value); (!ok || tag.Options.SkipFlagValue) && !tag.Options.Input {
+		// primitive flags require a valid default value
+		optional.tag.DefaultValue = zeroDefault(field.Type().Elem())
+	}
+	var probe = reflect.New(field.Type().Elem()).Elem()
+	value, err := newFlagValue(flagset, fieldName, probe, &optional.tag)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	optional.probe = value
+	flagset.Var(optional, tag.Name, tag.Description)
+	return probe, nil
+}
+
+// newFlagValue creates the flag value for a field without registering it in
+// the provided flag set. The value is created using a scratch flag set, which
+// must not be used any further.
+func newFlagValue(flagset *flag.FlagSet, fieldName string, fieldValue reflect.Value, tag *flagTag) (flag.Value, error) {
+	var scratch = flag.NewFlagSet(tag.Name, flag.ContinueOnError)
+	if err := registerFlag(fieldName, fieldValue, tag, scratch); err != nil {
+		return nil, err
+	}
+	var value = scratch.Lookup(tag.Name).Value
+	if input, ok := value.(*inputValue); ok {
+		// standard input must be claimed in the state of the actual flag set
+		input.flagset = flagset
+	}
+	return value, nil
+}
+
+// zeroDefault returns the textual representation of the zero value of the
+// type, as accepted as default value by registerFlagByPrimitive.
+func zeroDefault(typ reflect.Type) string {
+	if typ == reflect.TypeOf(time.Duration(0)) {
+		return "0s"
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "false"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
+		return "0"
+	default:
+		return ""
+	}
+}
+
+// isOptionalFlag checks whether the flag value belongs to a nil pointer field,
+// i.e. a flag without default value.
+func isOptionalFlag(value flag.Value) bool {
+	for {
+		if _, ok := value.(*optionalValue); ok {
+			return true
+		}
+		wrapped, ok := value.(wrappedValue)
+		if !ok || wrapped.unwrap() == nil {
+			return false
+		}
+		value = wrapped.unwrap()
+	}
+}
+
+// optionalValue is the flag value of a nil pointer field. The field's value is
+// allocated the first time the flag is set.
+type optionalValue struct {
+	flagset   *flag.FlagSet
+	field     reflect.Value
+	fieldName string
+	tag       flagTag
+	// probe is the flag value of a zero value of the field's element type.
+	// It stands in for the field's flag value until the flag is set.
+	probe flag.Value
+	// value is the flag value of the allocated field value, or nil if the
+	// flag has not been set.
+	value flag.Value
+}
+
+func (o *optionalValue) unwrap() flag.Value {
+	if o.value == nil {
+		return o.probe
+	}
+	return o.value
+}
+
+func (o *optionalValue) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return o.value.String()
+}
+
+func (o *optionalValue) Set(s string) error {
+	if o.value != nil {
+		return o.value.Set(s)
+	}
+	var allocated = reflect.New(o.field.Type().Elem())
+	value, err := newFlagValue(o.flagset, o.fieldName, allocated.Elem(), &o.tag)
+	if err != nil {
+		return err
+	}
+	if err := value.Set(s); err != nil {
+		return err
+	}
+	o.field.Set(allocated)
+	o.value = value
+	return nil
+}
+
+func (o *optionalValue) IsBoolFlag() bool {
+	return isBoolFlag(o.probe)
+}
+
+func (o *optionalValue) Get() interface{} {
+	if getter, ok := o.value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return nil
+}
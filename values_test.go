@@ -0,0 +1,216 @@
+package flagtag
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeprecatedFlag(t *testing.T) {
+	var s = struct {
+		Old int  `flag:"old,1,Old flag." flagopt:"deprecated=use -new"`
+		New int  `flag:"new,1,New flag."`
+		B   bool `flag:"b,false,Deprecated bool." flagopt:"deprecated"`
+	}{}
+	var output, warnings bytes.Buffer
+	fs := flag.NewFlagSet("deprecated", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	SetWarningOutput(fs, &warnings)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-old", "2", "-old=3", "-b", "-new", "4"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.Old != 3 || s.New != 4 || !s.B {
+		t.Fatal("Expected deprecated flags to still be set, but got", s)
+	}
+	expected := "warning: flag -old is deprecated: use -new\nwarning: flag -b is deprecated: no longer supported\n"
+	if warnings.String() != expected {
+		t.Fatal("Unexpected warnings:", warnings.String())
+	}
+	if output.Len() != 0 {
+		t.Fatal("Expected nothing to be written to the flag set's output, but got", output.String())
+	}
+}
+
+func TestDeprecatedFlagDefaultWarningOutput(t *testing.T) {
+	var s = struct {
+		Old string `flag:"old,,Old flag." flagopt:"deprecated=use -new"`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("deprecatedoutput", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-old", "value"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if output.String() != "warning: flag -old is deprecated: use -new\n" {
+		t.Fatal("Expected warning on the flag set's output, but got", output.String())
+	}
+}
+
+func TestDeprecatedFlagUsage(t *testing.T) {
+	var s = struct {
+		Old int `flag:"old,5,Old flag." flagopt:"deprecated=use -new"`
+	}{}
+	fs := flag.NewFlagSet("deprecatedusage", flag.ContinueOnError)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var b strings.Builder
+	writeDefaults(&b, fs, 80)
+	if b.String() != "  -old int\n        Old flag. (default 5) (deprecated: use -new)\n" {
+		t.Fatal("Unexpected usage output:", b.String())
+	}
+}
+
+func TestHiddenFlag(t *testing.T) {
+	var s = struct {
+		Visible bool `flag:"visible,false,Visible flag."`
+		Secret  bool `flag:"visibly,false,Hidden flag." flagopt:"hidden"`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("hidden", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-visibly"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !s.Secret {
+		t.Fatal("Expected hidden flag to be parsed.")
+	}
+	var b strings.Builder
+	writeDefaults(&b, fs, 80)
+	if b.String() != "  -visible\n        Visible flag.\n" {
+		t.Fatal("Unexpected usage output:", b.String())
+	}
+	if suggestions := suggestFlags(fs, "visibl"); len(suggestions) != 1 || suggestions[0] != "visible" {
+		t.Fatal("Expected hidden flag to be excluded from suggestions, but got", suggestions)
+	}
+}
+
+func TestRenamedFlag(t *testing.T) {
+	var s = struct {
+		Verbosity int `flag:"verbosity,1,Verbosity level." flagopt:"was=verbose-level,was=v"`
+	}{}
+	var output, warnings bytes.Buffer
+	fs := flag.NewFlagSet("renamed", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	SetWarningOutput(fs, &warnings)
+	EnableAbbreviations(fs)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-verbose-level", "3"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.Verbosity != 3 {
+		t.Fatal("Expected former flag name to set the field, but got", s.Verbosity)
+	}
+	if warnings.String() != "warning: flag -verbose-level is deprecated: use -verbosity\n" {
+		t.Fatal("Unexpected warnings:", warnings.String())
+	}
+	if err := fs.Parse([]string{"-v", "4"}); err != nil || s.Verbosity != 4 {
+		t.Fatal("Expected second former flag name to set the field, but got", s.Verbosity, err)
+	}
+	if f := fs.Lookup("verbose-level"); f == nil || f.DefValue != "1" || f.Usage != "Verbosity level." {
+		t.Fatal("Expected former flag name to be registered as alias.")
+	}
+	var b strings.Builder
+	writeDefaults(&b, fs, 80)
+	if b.String() != "  -verbosity int\n        Verbosity level. (default 1)\n" {
+		t.Fatal("Unexpected usage output:", b.String())
+	}
+	if result, err := expandAbbreviations(fs, []string{"-verb"}); err != nil || result[0] != "-verbosity" {
+		t.Fatal("Expected former flag names to be ignored for abbreviations, but got", result, err)
+	}
+}
+
+func TestOptionalFlag(t *testing.T) {
+	var s = struct {
+		Timeout *int    `flag:"timeout,,Timeout in seconds."`
+		Name    *string `flag:"name,,Name of the thing." flagopt:"was=title"`
+	}{}
+	fs := flag.NewFlagSet("optional", flag.ContinueOnError)
+	SetWarningOutput(fs, io.Discard)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-title", "foo"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.Timeout != nil {
+		t.Fatal("Expected timeout to stay nil, but got", *s.Timeout)
+	}
+	if s.Name == nil || *s.Name != "foo" {
+		t.Fatal("Expected name to be set through its former name.")
+	}
+	if !IsSet(&s, &s.Name) || IsSet(&s, &s.Timeout) {
+		t.Fatal("Expected only name to be set.")
+	}
+}
+
+func TestOptionalFlagUsage(t *testing.T) {
+	var s = struct {
+		Timeout *int `flag:"timeout,,Timeout in seconds."`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("optionalusage", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	if err := ConfigureFlagset(&s, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	PrintDefaults(fs)
+	expected := "  -timeout int\n        Timeout in seconds.\n"
+	if output.String() != expected {
+		t.Fatal("Unexpected usage:", output.String())
+	}
+}
+
+func TestSecretFlag(t *testing.T) {
+	var s = struct {
+		Password string `flag:"password,hunter2,Password of the user." flagopt:"secret,was=pass"`
+		Token    int    `flag:"token,1234,API token." flagopt:"secret"`
+		Empty    string `flag:"empty,,Empty secret." flagopt:"secret"`
+	}{}
+	var output bytes.Buffer
+	fs := flag.NewFlagSet("secret", flag.ContinueOnError)
+	fs.SetOutput(&output)
+	SetWarningOutput(fs, io.Discard)
+	if err := ConfigureFlagsetAndParseArgs(&s, fs, []string{"-pass", "s3cret"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if s.Password != "s3cret" || s.Token != 1234 {
+		t.Fatal("Expected secret values to be set, but got", s)
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		if strings.Contains(f.Value.String(), "s3cret") || strings.Contains(f.Value.String(), "1234") ||
+			strings.Contains(f.DefValue, "hunter2") || strings.Contains(f.DefValue, "1234") {
+			t.Error("Expected secret flag", f.Name, "to be redacted.")
+		}
+	})
+	if v := fs.Lookup("password").Value.String(); v != "******" {
+		t.Error("Expected redacted value, but got", v)
+	}
+	if v := fs.Lookup("empty").Value.String(); v != "" {
+		t.Error("Expected empty value to stay empty, but got", v)
+	}
+	PrintDefaults(fs)
+	fs.PrintDefaults()
+	if strings.Contains(output.String(), "default") {
+		t.Error("Expected no default values in usage information, but got", output.String())
+	}
+	output.Reset()
+	if err := WriteSources(&output, fs); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if strings.Contains(output.String(), "s3cret") || strings.Contains(output.String(), "1234") {
+		t.Error("Expected redacted sources, but got", output.String())
+	}
+	output.Reset()
+	if err := WriteConfig(&output, fs, "env", false); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if expected := "PASSWORD='******'\nTOKEN='******'\nEMPTY=\n"; output.String() != expected {
+		t.Error("Unexpected configuration:", output.String())
+	}
+	if args := FormatArgs(fs, false); len(args) != 1 || args[0] != "-password=s3cret" {
+		t.Error("Expected the actual value of the secret flag, but got", args)
+	}
+	if args := FormatRedactedArgs(fs, true); !reflect.DeepEqual(args, []string{"-password=******", "-token=******", "-empty="}) {
+		t.Error("Expected redacted values of the secret flags, but got", args)
+	}
+}